@@ -0,0 +1,54 @@
+package gori
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// Action is a user-defined command offered in the visit menu
+type Action struct {
+	// Key selects the action in the visit menu
+	Key string `json:"key"`
+	// Label describes the action in the visit menu
+	Label string `json:"label"`
+	// Command is a template rendered per project, e.g. "make -C {{.Path}} test"
+	Command string `json:"command"`
+}
+
+// actionData is what an action's command template is rendered with
+type actionData struct {
+	Path string
+	Name string
+}
+
+// Args renders the action's command for the given project. Every word of the
+// command is rendered on its own, so a substituted path containing spaces
+// stays a single argument.
+func (a Action) Args(project ProjectStatus) ([]string, error) {
+	absPath, err := filepath.Abs(project.Path)
+	if err != nil {
+		return nil, fmt.Errorf("resolving %s: %w", project.Path, err)
+	}
+	data := actionData{Path: absPath, Name: filepath.Base(absPath)}
+
+	var args []string
+	for _, word := range strings.Fields(a.Command) {
+		tmpl, err := template.New(a.Key).Option("missingkey=error").Parse(word)
+		if err != nil {
+			return nil, fmt.Errorf("parsing command of action %q: %w", a.Key, err)
+		}
+		var b bytes.Buffer
+		if err := tmpl.Execute(&b, data); err != nil {
+			return nil, fmt.Errorf("rendering command of action %q: %w", a.Key, err)
+		}
+		args = append(args, b.String())
+	}
+
+	if len(args) == 0 {
+		return nil, fmt.Errorf("action %q has no command", a.Key)
+	}
+	return args, nil
+}
@@ -0,0 +1,51 @@
+package gori
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestAction_Args(t *testing.T) {
+	projectPath, _ := filepath.Abs("my projects/foo")
+	tests := []struct {
+		name    string
+		command string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "path stays a single argument",
+			command: "make -C {{.Path}} test",
+			want:    []string{"make", "-C", projectPath, "test"},
+		},
+		{
+			name:    "name of the project",
+			command: "echo {{.Name}}",
+			want:    []string{"echo", "foo"},
+		},
+		{
+			name:    "unknown field",
+			command: "echo {{.Branch}}",
+			wantErr: true,
+		},
+		{
+			name:    "empty command",
+			command: "",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			action := Action{Key: "t", Label: "test", Command: tt.command}
+			got, err := action.Args(NewProject("my projects/foo", true, false, true))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Args() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Args() = %v, expected = %v", got, tt.want)
+			}
+		})
+	}
+}
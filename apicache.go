@@ -0,0 +1,141 @@
+package gori
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// CachingTransport caches the responses to GET requests on disk. A response
+// younger than TTL is reused as is, an older one is revalidated with its ETag,
+// which does not count against the rate limit of most hosting APIs.
+type CachingTransport struct {
+	// Dir holds a file per cached response
+	Dir string
+	TTL time.Duration
+	// Base makes the actual requests, http.DefaultTransport when nil
+	Base http.RoundTripper
+}
+
+// cachedResponse is a response as stored on disk
+type cachedResponse struct {
+	URL       string    `json:"url"`
+	Status    int       `json:"status"`
+	ETag      string    `json:"etag,omitempty"`
+	Body      []byte    `json:"body"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// RoundTrip answers GET requests from the cache when it can
+func (t *CachingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if req.Method != http.MethodGet {
+		return base.RoundTrip(req)
+	}
+
+	file := t.file(req)
+	cached, _ := readCachedResponse(file)
+	if cached != nil && time.Since(cached.FetchedAt) < t.TTL {
+		slog.Debug("cached API response", "url", cached.URL, "age", time.Since(cached.FetchedAt).Round(time.Second))
+		return cached.response(req), nil
+	}
+
+	if cached != nil && cached.ETag != "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("If-None-Match", cached.ETag)
+	}
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		resp.Body.Close()
+		slog.Debug("revalidated API response", "url", cached.URL)
+		cached.FetchedAt = time.Now()
+		t.store(file, cached)
+		return cached.response(req), nil
+	}
+	// a missing repository is an answer worth remembering as well
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	t.store(file, &cachedResponse{
+		URL:       req.URL.String(),
+		Status:    resp.StatusCode,
+		ETag:      resp.Header.Get("ETag"),
+		Body:      body,
+		FetchedAt: time.Now(),
+	})
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
+
+// file is where the response to the request is cached. The credentials are
+// part of the key, as they decide what the response holds.
+func (t *CachingTransport) file(req *http.Request) string {
+	key := sha256.New()
+	fmt.Fprintln(key, req.URL.String())
+	for _, header := range []string{"Accept", "Authorization", "Private-Token"} {
+		fmt.Fprintln(key, req.Header.Get(header))
+	}
+	return filepath.Join(t.Dir, hex.EncodeToString(key.Sum(nil))[:16]+".json")
+}
+
+// store writes the response to the cache, failing to is not worth more than
+// a log line
+func (t *CachingTransport) store(file string, cached *cachedResponse) {
+	content, err := json.Marshal(cached)
+	if err == nil {
+		err = os.MkdirAll(t.Dir, 0700)
+	}
+	if err == nil {
+		err = os.WriteFile(file, content, 0600)
+	}
+	if err != nil {
+		slog.Debug("caching API response", "url", cached.URL, "err", err)
+	}
+}
+
+func readCachedResponse(file string) (*cachedResponse, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var cached cachedResponse
+	if err := json.Unmarshal(content, &cached); err != nil {
+		return nil, err
+	}
+	return &cached, nil
+}
+
+// response turns the cached response into the answer to req
+func (c *cachedResponse) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", c.Status, http.StatusText(c.Status)),
+		StatusCode:    c.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Etag": {c.ETag}},
+		Body:          io.NopCloser(bytes.NewReader(c.Body)),
+		ContentLength: int64(len(c.Body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,54 @@
+package gori
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCachingTransport(t *testing.T) {
+	var requests, revalidations int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			revalidations++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		fmt.Fprint(w, `{"default_branch": "main"}`)
+	}))
+	defer server.Close()
+
+	transport := &CachingTransport{Dir: t.TempDir(), TTL: time.Hour, Base: server.Client().Transport}
+	client := &http.Client{Transport: transport}
+	get := func() string {
+		t.Helper()
+		resp, err := client.Get(server.URL + "/repos/me/tool")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("status = %v, expected 200", resp.Status)
+		}
+		return string(body)
+	}
+
+	want := `{"default_branch": "main"}`
+	if got := get(); got != want || requests != 1 {
+		t.Errorf("first request = %q after %d requests, expected %q after 1", got, requests, want)
+	}
+	if got := get(); got != want || requests != 1 {
+		t.Errorf("fresh request = %q after %d requests, expected the cached %q", got, requests, want)
+	}
+
+	transport.TTL = 0
+	if got := get(); got != want || requests != 2 || revalidations != 1 {
+		t.Errorf("stale request = %q after %d requests and %d revalidations, expected %q after one revalidation", got, requests, revalidations, want)
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hansbogert/gori"
+)
+
+// ack is the acknowledgement of a repository's findings, the (k) action
+// while visiting. It holds as long as the repository's fingerprint does.
+type ack struct {
+	Fingerprint string    `json:"fingerprint"`
+	AckedAt     time.Time `json:"acked_at"`
+}
+
+// acksFile returns where the acknowledgements are kept
+func acksFile() (string, error) {
+	cacheDir, err := gori.CacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cacheDir, "acks.json"), nil
+}
+
+// readAcks returns the acknowledgements by absolute repository path, none when
+// there is no acks file yet
+func readAcks() (map[string]ack, error) {
+	acks := map[string]ack{}
+	file, err := acksFile()
+	if err != nil {
+		return acks, err
+	}
+	b, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return acks, nil
+	}
+	if err != nil {
+		return acks, err
+	}
+	if err := json.Unmarshal(b, &acks); err != nil {
+		return map[string]ack{}, fmt.Errorf("reading %s: %w", file, err)
+	}
+	return acks, nil
+}
+
+// acked reports whether the findings of the project are acknowledged, that
+// is it did not change since it was
+func acked(acks map[string]ack, project gori.ProjectStatus) bool {
+	a, ok := acks[gori.AbsPath(project.Path)]
+	return ok && a.Fingerprint == project.Fingerprint()
+}
+
+// ackProject acknowledges the current findings of the project. Acks of
+// repositories which are gone are dropped meanwhile.
+func ackProject(project gori.ProjectStatus) error {
+	acks, err := readAcks()
+	if err != nil {
+		return err
+	}
+	for path := range acks {
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			delete(acks, path)
+		}
+	}
+	acks[gori.AbsPath(project.Path)] = ack{Fingerprint: project.Fingerprint(), AckedAt: time.Now()}
+
+	b, err := json.MarshalIndent(acks, "", "  ")
+	if err != nil {
+		return err
+	}
+	file, err := acksFile()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	return writeFileAtomic(file, b)
+}
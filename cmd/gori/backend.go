@@ -0,0 +1,78 @@
+package main
+
+import (
+	"time"
+
+	"github.com/hansbogert/gori"
+)
+
+// Backend checks the repositories of one version control system. The scanner
+// and the reporters only go through it, so another system plugs in by
+// implementing it and joining backends.
+type Backend interface {
+	// Name names the version control system, e.g. git
+	Name() string
+	// Discover reports whether the directory is a repository of the backend
+	Discover(repoPath string) bool
+	// Status reports whether the working directory has uncommitted changes,
+	// describing them
+	Status(repoPath string) (dirty bool, changes string, err error)
+	// UpstreamState reports whether all the work is upstreamed, along with
+	// the problems which kept it from telling for sure
+	UpstreamState(repoPath string) (upstreamed bool, problems []string, err error)
+	// Stashes counts the work set aside outside the history, like git's
+	// stashes or Mercurial's shelves
+	Stashes(repoPath string) (int, error)
+	// Details returns the time of the last commit and the URL of the origin,
+	// each the zero value when unknown
+	Details(repoPath string) (lastCommit time.Time, remoteURL string)
+}
+
+// hostedBackend is a backend which can also look up what the hosting provider
+// knows of a repository, for --pull-requests, --ci and --orphans. upstreamed
+// is what UpstreamState reported, before the repository's checks applied.
+type hostedBackend interface {
+	LookUpHosting(repoPath string, upstreamed bool, project *gori.ProjectStatus) []string
+}
+
+// explainingBackend is a backend which can also tell why the upstream and
+// stash checks fired, for --explain
+type explainingBackend interface {
+	// ExplainUnpushed lists the commits which are not upstreamed, under a
+	// line telling what they were compared with
+	ExplainUnpushed(repoPath string) []string
+	// ExplainStashes lists the stashes, newest first
+	ExplainStashes(repoPath string) []string
+}
+
+// divergingBackend is a backend which can also tell whether the checked out
+// branch diverged from its upstream, to report it as such instead of merely
+// not upstreamed
+type divergingBackend interface {
+	// Divergence returns the upstream of the checked out branch and how many
+	// commits each has which the other lacks, an empty upstream when there
+	// is none
+	Divergence(repoPath string) (upstream string, ahead, behind int)
+}
+
+// recentBackend is a backend which can also list the latest work on the
+// checked out branch, for gori report --standup
+type recentBackend interface {
+	// RecentCommits returns the checked out branch and the subjects of its
+	// commits made since the time, newest first
+	RecentCommits(repoPath string, since time.Time) (branch string, subjects []string)
+}
+
+// backends are tried in order. git goes last and takes every directory, so one
+// which is not a repository at all is reported as such by git.
+var backends = []Backend{jjBackend{}, hgBackend{}, systemGitBackend{}, gitBackend{}}
+
+// backendFor returns the backend of the repository
+func backendFor(repoPath string) Backend {
+	for _, backend := range backends {
+		if backend.Discover(repoPath) {
+			return backend
+		}
+	}
+	return gitBackend{}
+}
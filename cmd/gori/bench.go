@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var benchRuns int
+
+// benchBackends are the git backends gori bench compares
+var benchBackends = []Backend{gitBackend{}, systemGitBackend{}}
+
+// newBenchCmd builds the command timing the git backends
+func newBenchCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "bench [path]",
+		Short:             "Time the go-git and system git backends on every repository",
+		RunE:              runBench,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeScanRoot,
+	}
+	cmd.Flags().IntVar(&benchRuns, "runs", 3, "check every repository this many times with each backend and keep the fastest")
+	return cmd
+}
+
+// runBench checks every git repository under the scan path with each backend
+// in turn and reports how long they took. The repositories are checked one at
+// a time, so the timings do not depend on --concurrency.
+func runBench(cmd *cobra.Command, args []string) error {
+	if benchRuns < 1 {
+		return fmt.Errorf("--runs must be at least 1, got %d", benchRuns)
+	}
+	scanPath := scanPathArg(args)
+
+	repoPaths, err := discoverGitRepos(scanPath)
+	if err != nil {
+		return err
+	}
+	slices.Sort(repoPaths)
+
+	table := newTable(os.Stdout)
+	fmt.Fprint(table, "Repository")
+	for _, backend := range benchBackends {
+		fmt.Fprintf(table, "\t%s", backend.Name())
+	}
+	fmt.Fprintln(table)
+
+	totals := make([]time.Duration, len(benchBackends))
+	for _, repoPath := range repoPaths {
+		fmt.Fprint(table, displayName(repoPath))
+		for i, backend := range benchBackends {
+			elapsed, err := benchRepo(backend, repoPath)
+			if err != nil {
+				fmt.Fprintf(table, "\t%s", err)
+				continue
+			}
+			totals[i] += elapsed
+			fmt.Fprintf(table, "\t%s", elapsed.Round(time.Microsecond))
+		}
+		fmt.Fprintln(table)
+	}
+
+	fmt.Fprint(table, "Total")
+	for _, total := range totals {
+		fmt.Fprintf(table, "\t%s", total.Round(time.Microsecond))
+	}
+	fmt.Fprintln(table)
+	return table.Flush()
+}
+
+// benchRepo runs the checks of gori status on the repository with the backend
+// benchRuns times and returns the fastest run
+func benchRepo(backend Backend, repoPath string) (time.Duration, error) {
+	var fastest time.Duration
+	for run := range benchRuns {
+		start := time.Now()
+		if _, _, err := backend.Status(repoPath); err != nil {
+			return 0, err
+		}
+		if _, _, err := backend.UpstreamState(repoPath); err != nil {
+			return 0, err
+		}
+		if _, err := backend.Stashes(repoPath); err != nil {
+			return 0, err
+		}
+		if elapsed := time.Since(start); run == 0 || elapsed < fastest {
+			fastest = elapsed
+		}
+	}
+	return fastest, nil
+}
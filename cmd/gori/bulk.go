@@ -0,0 +1,473 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/chzyer/readline"
+	"github.com/spf13/cobra"
+
+	"github.com/hansbogert/gori"
+)
+
+var onlyChecks []string
+var allRepos bool
+var fetchPrune bool
+var dryRun bool
+var confirm bool
+var gcAuto bool
+var pruneYes bool
+
+func newExecCmd() *cobra.Command {
+	execCmd := &cobra.Command{
+		Use:               "exec [path] -- <command> [args...]",
+		Short:             "Run a command in every flagged repository",
+		RunE:              runExec,
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: completeScanRoot,
+	}
+	execCmd.Flags().StringSliceVar(&onlyChecks, "only", nil, fmt.Sprintf("only repositories failing one of the checks %v", gori.ValidChecks))
+	execCmd.Flags().BoolVar(&allRepos, "all", false, "all repositories, not just the flagged ones")
+	_ = execCmd.RegisterFlagCompletionFunc("only", completeList(func(cmd *cobra.Command, args []string) []string {
+		return gori.ValidChecks
+	}))
+	return execCmd
+}
+
+func newFetchCmd() *cobra.Command {
+	fetchCmd := &cobra.Command{
+		Use:               "fetch [path]",
+		Short:             "Fetch the remotes of every repository concurrently",
+		RunE:              runFetch,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeScanRoot,
+	}
+	fetchCmd.Flags().BoolVar(&fetchPrune, "prune", false, "remove remote-tracking references that no longer exist on the remote")
+	return fetchCmd
+}
+
+func newPushCmd() *cobra.Command {
+	pushCmd := &cobra.Command{
+		Use:               "push [path]",
+		Short:             "Push branches which are strictly ahead of their upstream",
+		RunE:              runPush,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeScanRoot,
+	}
+	pushCmd.Flags().BoolVarP(&dryRun, "dry-run", "n", false, "only show what would be pushed")
+	pushCmd.Flags().BoolVarP(&confirm, "confirm", "i", false, "ask for confirmation before pushing each repository")
+	return pushCmd
+}
+
+func newPruneBranchesCmd() *cobra.Command {
+	pruneCmd := &cobra.Command{
+		Use:               "prune-branches [path]",
+		Short:             "Delete local branches which are merged or whose upstream is gone",
+		RunE:              runPruneBranches,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeScanRoot,
+	}
+	pruneCmd.Flags().BoolVarP(&dryRun, "dry-run", "n", false, "only list the branches which can be deleted")
+	pruneCmd.Flags().BoolVarP(&pruneYes, "yes", "y", false, "delete them without asking for confirmation")
+	return pruneCmd
+}
+
+func newGCCmd() *cobra.Command {
+	gcCmd := &cobra.Command{
+		Use:               "gc [path]",
+		Short:             "Run git maintenance in every repository concurrently",
+		RunE:              runGC,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeScanRoot,
+	}
+	gcCmd.Flags().BoolVar(&gcAuto, "auto", false, "only run maintenance in repositories which need it")
+	return gcCmd
+}
+
+// runExec runs the command following "--" in the selected repositories,
+// prefixing every line of output with the repository's name
+func runExec(cmd *cobra.Command, args []string) error {
+	dash := cmd.ArgsLenAtDash()
+	if dash < 0 || dash == len(args) {
+		return errors.New("missing command, separate it from gori's arguments with --")
+	}
+	if dash > 1 {
+		return fmt.Errorf("expected at most one path before --, got %v", args[:dash])
+	}
+	scanPath := "./"
+	if dash == 1 {
+		scanPath = args[0]
+	}
+	command := args[dash:]
+
+	repoPaths, err := selectRepos(scanPath, allRepos, onlyChecks)
+	if err != nil {
+		return err
+	}
+
+	var outMu sync.Mutex
+	errs := forEachRepo(repoPaths, func(repoPath string) error {
+		name := filepath.Base(repoPath)
+		stdout := &prefixWriter{mu: &outMu, out: os.Stdout, prefix: name}
+		stderr := &prefixWriter{mu: &outMu, out: os.Stderr, prefix: name}
+		c := exec.Command(command[0], command[1:]...)
+		c.Dir = repoPath
+		c.Stdout = stdout
+		c.Stderr = stderr
+		err := c.Run()
+		stdout.Flush()
+		stderr.Flush()
+		if err != nil {
+			outMu.Lock()
+			fmt.Fprintf(os.Stderr, "%s: %s\n", name, err)
+			outMu.Unlock()
+		}
+		return err
+	})
+
+	if len(errs) > 0 {
+		return fmt.Errorf("command failed in %d of %d repositories: %s", len(errs), len(repoPaths), strings.Join(repoNames(errs), ", "))
+	}
+	return nil
+}
+
+// forEachRepo calls fn for every repository, at most concurrency at a time,
+// and returns the errors by repository path
+func forEachRepo(repoPaths []string, fn func(repoPath string) error) map[string]error {
+	var mu sync.Mutex
+	errs := make(map[string]error)
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+	for _, repoPath := range repoPaths {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if err := fn(repoPath); err != nil {
+				mu.Lock()
+				errs[repoPath] = err
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return errs
+}
+
+// repoNames returns the sorted directory names of the failed repositories
+func repoNames(errs map[string]error) []string {
+	var names []string
+	for repoPath := range errs {
+		names = append(names, filepath.Base(repoPath))
+	}
+	slices.Sort(names)
+	return names
+}
+
+// runFetch fetches the remotes of every repository. The system git is used
+// so the user's credential helpers and ssh configuration apply.
+func runFetch(cmd *cobra.Command, args []string) error {
+	scanPath := scanPathArg(args)
+
+	repoPaths, err := discoverGitRepos(scanPath)
+	if err != nil {
+		return err
+	}
+
+	gitArgs := []string{"fetch", "--all", "--quiet"}
+	if fetchPrune {
+		gitArgs = append(gitArgs, "--prune")
+	}
+
+	var mu sync.Mutex
+	completed := 0
+	errs := forEachRepo(repoPaths, func(repoPath string) error {
+		c := exec.Command("git", gitArgs...)
+		c.Dir = repoPath
+		output, err := c.CombinedOutput()
+		if err != nil {
+			err = fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		completed++
+		result := "fetched"
+		if err != nil {
+			result = "failed"
+		}
+		fmt.Printf("[%d/%d] %s: %s\n", completed, len(repoPaths), filepath.Base(repoPath), result)
+		return err
+	})
+
+	if len(errs) > 0 {
+		fmt.Println("\nFailed to fetch:")
+		for _, repoPath := range slices.Sorted(maps.Keys(errs)) {
+			fmt.Printf("  %s: %s\n", filepath.Base(repoPath), errs[repoPath])
+		}
+		return fmt.Errorf("fetching failed in %d of %d repositories", len(errs), len(repoPaths))
+	}
+	return nil
+}
+
+// runPush pushes the branches which are strictly ahead of their upstream.
+// The system git is used so the user's credential helpers and ssh
+// configuration apply.
+func runPush(cmd *cobra.Command, args []string) error {
+	scanPath := scanPathArg(args)
+
+	repoPaths, err := discoverGitRepos(scanPath)
+	if err != nil {
+		return err
+	}
+
+	var rl *readline.Instance
+	if confirm && !dryRun {
+		rl, err = newPrompt()
+		if err != nil {
+			return fmt.Errorf("starting prompt: %w", err)
+		}
+		defer rl.Close()
+	}
+
+	pushes := make(map[string][]branchPush)
+	for _, repoPath := range repoPaths {
+		repo, err := gori.OpenRepo(repoPath)
+		if err != nil {
+			continue
+		}
+		branches, err := aheadBranches(repo)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", filepath.Base(repoPath), err)
+			continue
+		}
+		if len(branches) == 0 {
+			continue
+		}
+
+		for _, branch := range branches {
+			fmt.Printf("%s: %s\n", filepath.Base(repoPath), branch)
+		}
+		if rl != nil && !askYesNo(rl, fmt.Sprintf("Push %s? [y/N] ", filepath.Base(repoPath))) {
+			continue
+		}
+		pushes[repoPath] = branches
+	}
+
+	if dryRun || len(pushes) == 0 {
+		return nil
+	}
+
+	errs := forEachRepo(slices.Sorted(maps.Keys(pushes)), func(repoPath string) error {
+		for _, branch := range pushes[repoPath] {
+			c := exec.Command("git", "push", "--quiet", branch.remote, branch.branch+":"+branch.merge)
+			c.Dir = repoPath
+			if output, err := c.CombinedOutput(); err != nil {
+				return fmt.Errorf("pushing %s: %w: %s", branch, err, strings.TrimSpace(string(output)))
+			}
+		}
+		fmt.Printf("%s: pushed\n", filepath.Base(repoPath))
+		return nil
+	})
+
+	if len(errs) > 0 {
+		fmt.Println("\nFailed to push:")
+		for _, repoPath := range slices.Sorted(maps.Keys(errs)) {
+			fmt.Printf("  %s: %s\n", filepath.Base(repoPath), errs[repoPath])
+		}
+		return fmt.Errorf("pushing failed in %d of %d repositories", len(errs), len(pushes))
+	}
+	return nil
+}
+
+// runPruneBranches lists the local branches which are merged into the
+// mainish branch, squashed or rebased too, or whose upstream is gone, and
+// deletes them all after a single confirmation
+func runPruneBranches(cmd *cobra.Command, args []string) error {
+	scanPath := scanPathArg(args)
+
+	repoPaths, err := discoverGitRepos(scanPath)
+	if err != nil {
+		return err
+	}
+
+	prunes := make(map[string][]prunableBranch)
+	total := 0
+	for _, repoPath := range repoPaths {
+		repo, err := gori.OpenRepo(repoPath)
+		if err != nil {
+			continue
+		}
+		branches, err := prunableBranches(repo, repoPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", filepath.Base(repoPath), err)
+			continue
+		}
+		for _, branch := range branches {
+			fmt.Printf("%s: %s\n", filepath.Base(repoPath), branch)
+		}
+		if len(branches) > 0 {
+			prunes[repoPath] = branches
+			total += len(branches)
+		}
+	}
+
+	if dryRun || total == 0 {
+		return nil
+	}
+	if !pruneYes {
+		rl, err := newPrompt()
+		if err != nil {
+			return fmt.Errorf("starting prompt: %w", err)
+		}
+		defer rl.Close()
+		if !askYesNo(rl, fmt.Sprintf("Delete %d branch(es) in %d repositories? [y/N] ", total, len(prunes))) {
+			return nil
+		}
+	}
+
+	errs := forEachRepo(slices.Sorted(maps.Keys(prunes)), func(repoPath string) error {
+		var names []string
+		for _, branch := range prunes[repoPath] {
+			names = append(names, branch.branch)
+		}
+		// -D as git does not know a squashed or rebased branch is merged
+		c := exec.Command("git", append([]string{"branch", "--quiet", "-D"}, names...)...)
+		c.Dir = repoPath
+		if output, err := c.CombinedOutput(); err != nil {
+			return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+		}
+		fmt.Printf("%s: deleted %s\n", filepath.Base(repoPath), strings.Join(names, ", "))
+		return nil
+	})
+
+	if len(errs) > 0 {
+		fmt.Println("\nFailed to delete branches:")
+		for _, repoPath := range slices.Sorted(maps.Keys(errs)) {
+			fmt.Printf("  %s: %s\n", filepath.Base(repoPath), errs[repoPath])
+		}
+		return fmt.Errorf("deleting branches failed in %d of %d repositories", len(errs), len(prunes))
+	}
+	return nil
+}
+
+// runGC runs git maintenance in every repository and reports how much the
+// size of their git directories changed
+func runGC(cmd *cobra.Command, args []string) error {
+	scanPath := scanPathArg(args)
+
+	repoPaths, err := discoverGitRepos(scanPath)
+	if err != nil {
+		return err
+	}
+
+	gitArgs := []string{"maintenance", "run", "--quiet"}
+	if gcAuto {
+		gitArgs = append(gitArgs, "--auto")
+	}
+
+	var mu sync.Mutex
+	var totalBefore, totalAfter int64
+	errs := forEachRepo(repoPaths, func(repoPath string) error {
+		gitDir := filepath.Join(repoPath, ".git")
+		before := dirSize(gitDir)
+		c := exec.Command("git", gitArgs...)
+		c.Dir = repoPath
+		if output, err := c.CombinedOutput(); err != nil {
+			return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+		}
+		after := dirSize(gitDir)
+
+		mu.Lock()
+		defer mu.Unlock()
+		totalBefore += before
+		totalAfter += after
+		fmt.Printf("%s: %s -> %s\n", filepath.Base(repoPath), humanSize(before), humanSize(after))
+		return nil
+	})
+
+	fmt.Printf("\nTotal: %s -> %s\n", humanSize(totalBefore), humanSize(totalAfter))
+
+	if len(errs) > 0 {
+		fmt.Println("\nFailed to run maintenance:")
+		for _, repoPath := range slices.Sorted(maps.Keys(errs)) {
+			fmt.Printf("  %s: %s\n", filepath.Base(repoPath), errs[repoPath])
+		}
+		return fmt.Errorf("maintenance failed in %d of %d repositories", len(errs), len(repoPaths))
+	}
+	return nil
+}
+
+// dirSize sums the sizes of the regular files below path
+func dirSize(path string) int64 {
+	var size int64
+	filepath.WalkDir(path, func(_ string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
+
+// humanSize formats a size in bytes using binary units
+func humanSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+// prefixWriter writes every complete line prefixed with a name, so output of
+// concurrent commands can be told apart
+type prefixWriter struct {
+	mu     *sync.Mutex
+	out    io.Writer
+	prefix string
+	buf    []byte
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.mu.Lock()
+		fmt.Fprintf(w.out, "%s: %s\n", w.prefix, w.buf[:i])
+		w.mu.Unlock()
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush writes a trailing line not terminated by a newline
+func (w *prefixWriter) Flush() {
+	if len(w.buf) > 0 {
+		w.Write([]byte{'\n'})
+	}
+}
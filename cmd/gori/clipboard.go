@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+var copyReport bool
+
+// clipboardCommand returns the program putting its stdin on the clipboard,
+// GORI_CLIPBOARD if set, or nil when there is none to use
+func clipboardCommand() []string {
+	if command := os.Getenv("GORI_CLIPBOARD"); command != "" {
+		return strings.Fields(command)
+	}
+	// over ssh they would copy to the clipboard of the server, if any
+	if os.Getenv("SSH_CONNECTION") != "" || os.Getenv("SSH_TTY") != "" {
+		return nil
+	}
+
+	var candidates [][]string
+	switch runtime.GOOS {
+	case "darwin":
+		candidates = [][]string{{"pbcopy"}}
+	case "windows":
+		candidates = [][]string{{"clip.exe"}}
+	default:
+		if os.Getenv("WAYLAND_DISPLAY") != "" {
+			candidates = append(candidates, []string{"wl-copy"})
+		}
+		if os.Getenv("DISPLAY") != "" {
+			candidates = append(candidates, []string{"xclip", "-selection", "clipboard"}, []string{"xsel", "--clipboard", "--input"})
+		}
+	}
+	for _, candidate := range candidates {
+		if _, err := exec.LookPath(candidate[0]); err == nil {
+			return candidate
+		}
+	}
+	return nil
+}
+
+// copyToClipboard puts the text on the clipboard with the clipboard program,
+// or without one, like over ssh, asks the terminal to with OSC 52
+func copyToClipboard(text []byte) error {
+	args := clipboardCommand()
+	if args == nil {
+		return copyOSC52(text)
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = bytes.NewReader(text)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("copying with %s: %w: %s", args[0], err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// copyOSC52 writes the OSC 52 sequence setting the clipboard to the terminal.
+// tmux only passes it on to the terminal wrapped in its own escape.
+func copyOSC52(text []byte) error {
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		return fmt.Errorf("no clipboard program found nor a terminal to copy through: %w", err)
+	}
+	defer tty.Close()
+
+	seq := "\x1b]52;c;" + base64.StdEncoding.EncodeToString(text) + "\a"
+	if os.Getenv("TMUX") != "" {
+		seq = "\x1bPtmux;\x1b" + seq + "\x1b\\"
+	}
+	_, err = io.WriteString(tty, seq)
+	return err
+}
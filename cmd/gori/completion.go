@@ -0,0 +1,83 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/hansbogert/gori"
+)
+
+// completeScanRoot completes the optional [path] argument of a command with
+// directories only
+func completeScanRoot(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return nil, cobra.ShellCompDirectiveFilterDirs
+}
+
+// completeValues completes a flag or argument with one of the given values
+func completeValues(values []string) cobra.CompletionFunc {
+	return cobra.FixedCompletions(values, cobra.ShellCompDirectiveNoFileComp)
+}
+
+// completeList completes a comma separated list flag, offering values for
+// the element being typed while keeping the ones before it
+func completeList(values func(cmd *cobra.Command, args []string) []string) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		prefix := ""
+		if i := strings.LastIndex(toComplete, ","); i >= 0 {
+			prefix = toComplete[:i+1]
+		}
+		var completions []string
+		for _, value := range values(cmd, args) {
+			completions = append(completions, prefix+value)
+		}
+		return completions, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// discoveredNames lists the names of the repositories in the scan root, for
+// completing the repository arguments and flags
+func discoveredNames(scanPath string) []string {
+	repoPaths, err := discoverRepos(scanPath)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, repoPath := range repoPaths {
+		names = append(names, filepath.Base(repoPath))
+	}
+	return names
+}
+
+// completeSnoozeRepo completes the repository of gori snooze add with the
+// repositories in --path
+func completeSnoozeRepo(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return discoveredNames(snoozePath), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeSnoozed completes the arguments of gori snooze rm, first the repos
+// listed in the ignore file of --path and then the check to remove
+func completeSnoozed(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	switch len(args) {
+	case 0:
+		config, err := gori.LoadIgnoreConfig(snoozePath)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		var repos []string
+		for _, repo := range config.Repos {
+			repos = append(repos, repo.Path)
+		}
+		return repos, cobra.ShellCompDirectiveNoFileComp
+	case 1:
+		return gori.ValidChecks, cobra.ShellCompDirectiveNoFileComp
+	}
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var concurrencyFlag string
+
+// networkFilesystems are the filesystem types whose latency, rather than the
+// disk, limits how fast repositories can be checked
+var networkFilesystems = []string{"nfs", "nfs4", "cifs", "smb3", "smbfs", "9p", "afs", "ceph", "glusterfs"}
+
+// isNetworkFilesystem reports whether the filesystem type is remote. FUSE
+// filesystems count as such, as most of them, like sshfs or rclone, are.
+func isNetworkFilesystem(fsType string) bool {
+	return slices.Contains(networkFilesystems, fsType) || fsType == "fuse" || strings.HasPrefix(fsType, "fuse.")
+}
+
+// resolveConcurrency turns the --concurrency value into a number of workers,
+// sizing it for the storage holding path when the value is auto
+func resolveConcurrency(value string, path string) (int, error) {
+	if value != "auto" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return 0, fmt.Errorf("invalid concurrency %q, use a positive number or auto", value)
+		}
+		return n, nil
+	}
+
+	storage := storageKind(path)
+	n := autoConcurrency(storage, runtime.NumCPU())
+	slog.Info("sized the worker pool", "storage", storage, "cpus", runtime.NumCPU(), "concurrency", n)
+	return n, nil
+}
+
+// autoConcurrency picks the number of workers for a kind of storage. Solid
+// state keeps up with more workers than there are CPUs, as part of the work
+// waits on IO. Spinning disks thrash when seeking for several workers at once,
+// network filesystems benefit from some parallelism without flooding the
+// server.
+func autoConcurrency(storage string, cpus int) int {
+	switch storage {
+	case "ssd":
+		return 2 * cpus
+	case "rotational":
+		return 2
+	case "network":
+		return min(4, cpus)
+	default:
+		return cpus
+	}
+}
+
+// storageKind guesses the storage holding path: ssd, rotational, network, or
+// unknown when it cannot tell, e.g. on systems without /proc
+func storageKind(path string) string {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "unknown"
+	}
+	if resolved, err := filepath.EvalSymlinks(absPath); err == nil {
+		absPath = resolved
+	}
+
+	f, err := os.Open("/proc/self/mountinfo")
+	if err != nil {
+		return "unknown"
+	}
+	defer f.Close()
+
+	m, ok := mountFor(f, absPath)
+	if !ok {
+		return "unknown"
+	}
+	if isNetworkFilesystem(m.fsType) {
+		return "network"
+	}
+
+	// partitions have no queue of their own, their disk does
+	for _, queue := range []string{"queue/rotational", "../queue/rotational"} {
+		b, err := os.ReadFile(filepath.Join("/sys/dev/block", m.device, queue))
+		if err != nil {
+			continue
+		}
+		if strings.TrimSpace(string(b)) == "1" {
+			return "rotational"
+		}
+		return "ssd"
+	}
+	return "unknown"
+}
+
+// mount is a line of /proc/self/mountinfo
+type mount struct {
+	point  string
+	device string
+	fsType string
+}
+
+// mountFor returns the mount holding path, the one with the longest mount
+// point containing it
+func mountFor(mountinfo io.Reader, path string) (mount, bool) {
+	var best mount
+	found := false
+	scanner := bufio.NewScanner(mountinfo)
+	for scanner.Scan() {
+		// 36 35 98:0 /mnt1 /mnt/parent rw,noatime master:1 - ext3 /dev/root rw
+		fields := strings.Fields(scanner.Text())
+		sep := slices.Index(fields, "-")
+		if len(fields) < 5 || sep < 0 || sep+1 >= len(fields) {
+			continue
+		}
+		m := mount{point: unescapeMountPoint(fields[4]), device: fields[2], fsType: fields[sep+1]}
+		if !within(path, m.point) {
+			continue
+		}
+		if !found || len(m.point) >= len(best.point) {
+			best, found = m, true
+		}
+	}
+	return best, found
+}
+
+// within reports whether path is dir or below it
+func within(path string, dir string) bool {
+	if dir == "/" {
+		return strings.HasPrefix(path, "/")
+	}
+	return path == dir || strings.HasPrefix(path, dir+"/")
+}
+
+// unescapeMountPoint undoes the octal escaping of spaces and the like in
+// mountinfo
+func unescapeMountPoint(point string) string {
+	var b strings.Builder
+	for i := 0; i < len(point); i++ {
+		if point[i] == '\\' && i+3 < len(point) {
+			if c, err := strconv.ParseUint(point[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(c))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(point[i])
+	}
+	return b.String()
+}
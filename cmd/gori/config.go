@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/hansbogert/gori"
+)
+
+// newConfigCmd groups the commands maintaining gori's config files
+func newConfigCmd() *cobra.Command {
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "Maintain gori's config files",
+	}
+
+	migrateCmd := &cobra.Command{
+		Use:               "migrate [path]",
+		Short:             "Upgrade the scan root's ignore file to the current layout",
+		RunE:              runConfigMigrate,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeScanRoot,
+	}
+
+	migrateCmd.Flags().BoolVarP(&dryRun, "dry-run", "n", false, "print the changes to the ignore file instead of writing it")
+
+	validateCmd := &cobra.Command{
+		Use:               "validate [path]",
+		Short:             "Check the config files applying to a scan root, failing on problems",
+		RunE:              runConfigValidate,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeScanRoot,
+	}
+
+	configCmd.AddCommand(migrateCmd, validateCmd)
+	return configCmd
+}
+
+func runConfigMigrate(cmd *cobra.Command, args []string) error {
+	scanPath := scanPathArg(args)
+	from, err := gori.MigrateIgnoreConfig(scanPath, dryRun)
+	if err != nil {
+		return err
+	}
+	if from == gori.IgnoreConfigVersion {
+		fmt.Printf("%s is up to date (version %d).\n", gori.IgnoreFile(scanPath), from)
+		return nil
+	}
+	fmt.Printf("Migrated %s from version %d to %d.\n", gori.IgnoreFile(scanPath), from, gori.IgnoreConfigVersion)
+	return nil
+}
+
+func runConfigValidate(cmd *cobra.Command, args []string) error {
+	problems := gori.ValidateConfig(scanPathArg(args))
+	for _, problem := range problems {
+		fmt.Println(problem)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%d problem(s) found", len(problems))
+	}
+	fmt.Println("No problems found.")
+	return nil
+}
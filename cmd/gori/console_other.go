@@ -0,0 +1,9 @@
+//go:build !windows
+
+package main
+
+// legacyConsole reports whether stdout is the classic Windows console, which
+// only exists on Windows
+func legacyConsole() bool {
+	return false
+}
@@ -0,0 +1,20 @@
+//go:build windows
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// legacyConsole reports whether stdout is the classic Windows console, which
+// cannot show emoji. Windows Terminal and the terminals of editors like VS
+// Code announce themselves in the environment.
+func legacyConsole() bool {
+	var mode uint32
+	if err := syscall.GetConsoleMode(syscall.Handle(os.Stdout.Fd()), &mode); err != nil {
+		// not a console, e.g. a pipe
+		return false
+	}
+	return os.Getenv("WT_SESSION") == "" && os.Getenv("TERM_PROGRAM") == ""
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hansbogert/gori"
+)
+
+// errTooManyFiles stops counting the files of an oversized directory
+var errTooManyFiles = errors.New("too many files")
+
+// aloneRepos are the oversized directories which are checked one at a time,
+// with large_repos: "alone", as go-git holding several of their indexes and
+// objects at once can run the machine out of memory
+var aloneRepos = map[string]bool{}
+
+// guardDiscovery drops, or moves to the end, the candidates which would make
+// the scan slow: directories on a network mount other than the scan root's and
+// directories with more files than configured. The latter are put in
+// aloneRepos when they are to be checked alone.
+func guardDiscovery(repoPaths []string, scanPath string, settings *gori.Settings) []string {
+	networkAction := guardAction("network_mounts", settings.NetworkMounts, "skip", "last", "scan")
+	largeAction := guardAction("large_repos", settings.LargeRepos, "skip", "last", "alone")
+
+	// without /proc mounts cannot be told apart, which only disables the guard
+	mountinfo, _ := os.ReadFile("/proc/self/mountinfo")
+	mountOf := func(path string) (mount, bool) {
+		if absPath, err := filepath.Abs(path); err == nil {
+			if resolved, err := filepath.EvalSymlinks(absPath); err == nil {
+				return mountFor(bytes.NewReader(mountinfo), resolved)
+			}
+		}
+		return mount{}, false
+	}
+	rootMount, _ := mountOf(scanPath)
+
+	var kept, last []string
+	for _, repoPath := range repoPaths {
+		action := "scan"
+		if m, ok := mountOf(repoPath); ok && m.point != rootMount.point && isNetworkFilesystem(m.fsType) {
+			action = networkAction
+			slog.Info("found a network mount", "repo", repoPath, "fs", m.fsType, "action", action)
+		} else if settings.MaxRepoFiles > 0 && countFiles(repoPath, settings.MaxRepoFiles) > settings.MaxRepoFiles {
+			action = largeAction
+			slog.Info("found an oversized directory", "repo", repoPath, "max_files", settings.MaxRepoFiles, "action", action)
+		}
+
+		switch action {
+		case "skip":
+		case "last":
+			last = append(last, repoPath)
+		case "alone":
+			aloneRepos[repoPath] = true
+			last = append(last, repoPath)
+		default:
+			kept = append(kept, repoPath)
+		}
+	}
+	return append(kept, last...)
+}
+
+// guardAction returns the configured action of a guard, the first of the
+// valid ones when it is not set or unknown
+func guardAction(setting string, value string, valid ...string) string {
+	if value == "" {
+		return valid[0]
+	}
+	if !slices.Contains(valid, value) {
+		slog.Warn("unknown discovery action, using the default", "setting", setting, "value", value, "valid", strings.Join(valid, ", "))
+		return valid[0]
+	}
+	return value
+}
+
+// countFiles counts the worktree files under dir, stopping once there are
+// more than limit. The git directory is left out, checking the status does not
+// walk it.
+func countFiles(dir string, limit int) int {
+	count := 0
+	filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() && (d.Name() == ".git" || d.Name() == jjDir || d.Name() == hgDir) {
+			return filepath.SkipDir
+		}
+		if !d.IsDir() {
+			count++
+		}
+		if count > limit {
+			return errTooManyFiles
+		}
+		return nil
+	})
+	return count
+}
+
+var rediscover bool
+var includeHidden bool
+
+// discoveryCache is the outcome of discovering the repositories of a scan
+// root, as stored in the cache directory
+type discoveryCache struct {
+	Root         string    `json:"root"`
+	DiscoveredAt time.Time `json:"discovered_at"`
+	// Guards are the discovery guard settings the candidates were found with
+	Guards string `json:"guards"`
+	// Repos are the names of the candidates, relative to the root
+	Repos []string `json:"repos"`
+	// Alone are the names of the candidates to check one at a time
+	Alone []string `json:"alone,omitempty"`
+}
+
+// guardSettings describes the settings and flags affecting discovery, changing
+// them invalidates the cache
+func guardSettings(settings *gori.Settings) string {
+	return fmt.Sprintf("network_mounts=%s max_repo_files=%d large_repos=%s hidden=%t exclude_dirs=%q", settings.NetworkMounts, settings.MaxRepoFiles, settings.LargeRepos, includeHidden, settings.ExcludeDirs)
+}
+
+// discoveryCacheFile returns where the discovery of the scan root is cached
+func discoveryCacheFile(absRoot string) (string, error) {
+	return scanRootCacheFile(absRoot, "discovery", ".json")
+}
+
+// scanRootCacheFile returns the file of the scan root in the directory of the
+// cache dir, named after a hash of the root
+func scanRootCacheFile(absRoot string, dir string, ext string) (string, error) {
+	cacheDir, err := gori.CacheDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(absRoot))
+	return filepath.Join(cacheDir, dir, hex.EncodeToString(sum[:8])+ext), nil
+}
+
+// cachedDiscovery returns the cached candidates of the scan root, unless the
+// cache is older than the TTL, --rediscover is given, or the root changed
+// since, e.g. because a repository was cloned into it
+func cachedDiscovery(scanPath string, settings *gori.Settings) ([]string, bool) {
+	ttl, err := gori.ParseSnoozeDuration(cmp.Or(settings.DiscoveryCacheTTL, "1h"))
+	if err != nil {
+		slog.Warn("invalid discovery_cache_ttl, not caching", "err", err)
+		return nil, false
+	}
+	if rediscover || ttl == 0 {
+		return nil, false
+	}
+
+	absRoot, err := filepath.Abs(scanPath)
+	if err != nil {
+		return nil, false
+	}
+	cacheFile, err := discoveryCacheFile(absRoot)
+	if err != nil {
+		return nil, false
+	}
+	b, err := os.ReadFile(cacheFile)
+	if err != nil {
+		return nil, false
+	}
+	var cache discoveryCache
+	if err := json.Unmarshal(b, &cache); err != nil {
+		return nil, false
+	}
+
+	info, err := os.Stat(scanPath)
+	if err != nil || cache.Root != absRoot || cache.Guards != guardSettings(settings) || time.Since(cache.DiscoveredAt) > ttl || !info.ModTime().Before(cache.DiscoveredAt) {
+		return nil, false
+	}
+
+	var repoPaths []string
+	for _, name := range cache.Repos {
+		repoPaths = append(repoPaths, filepath.Join(scanPath, name))
+	}
+	for _, name := range cache.Alone {
+		aloneRepos[filepath.Join(scanPath, name)] = true
+	}
+	slog.Info("using cached discovery", "root", absRoot, "discovered_at", cache.DiscoveredAt.Format(time.DateTime))
+	return repoPaths, true
+}
+
+// cacheDiscovery remembers the candidates of the scan root. Failing to do so
+// only makes the next run slower, so it is merely logged.
+func cacheDiscovery(scanPath string, repoPaths []string, settings *gori.Settings) {
+	if err := writeDiscoveryCache(scanPath, repoPaths, settings); err != nil {
+		slog.Info("caching discovery", "err", err)
+	}
+}
+
+func writeDiscoveryCache(scanPath string, repoPaths []string, settings *gori.Settings) error {
+	absRoot, err := filepath.Abs(scanPath)
+	if err != nil {
+		return err
+	}
+	cache := discoveryCache{Root: absRoot, DiscoveredAt: time.Now(), Guards: guardSettings(settings)}
+	for _, repoPath := range repoPaths {
+		cache.Repos = append(cache.Repos, filepath.Base(repoPath))
+		if aloneRepos[repoPath] {
+			cache.Alone = append(cache.Alone, filepath.Base(repoPath))
+		}
+	}
+
+	cacheFile, err := discoveryCacheFile(absRoot)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(cacheFile), 0755); err != nil {
+		return err
+	}
+	b, err := json.Marshal(cache)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(cacheFile, b, 0644)
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/chzyer/readline"
+	"github.com/spf13/cobra"
+
+	"github.com/hansbogert/gori"
+)
+
+// diagnosis is the outcome of a single doctor check. A failed check comes with
+// a fix, a warning is only worth knowing about.
+type diagnosis struct {
+	name    string
+	detail  string
+	fix     string
+	failed  bool
+	warning bool
+}
+
+// newDoctorCmd builds the command checking gori's environment
+func newDoctorCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "doctor [path]",
+		Short:             "Check git, the config files, gori's directories, the terminal and credentials",
+		RunE:              runDoctor,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeScanRoot,
+	}
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	scanPath := scanPathArg(args)
+	settings := loadSettings()
+
+	diagnoses := []diagnosis{
+		checkGit(),
+		checkConfig(scanPath),
+		checkCacheDir(),
+		checkTerminal(),
+		checkEmoji(),
+		checkCredentials(),
+		checkCommand("editor", func() ([]string, error) { return editorCommand(settings) }),
+		checkCommand("git UI", func() ([]string, error) { return gitUICommand(settings) }),
+	}
+
+	failed := 0
+	for _, d := range diagnoses {
+		mark := "ok"
+		switch {
+		case d.failed:
+			mark = "FAIL"
+			failed++
+		case d.warning:
+			mark = "warn"
+		}
+		fmt.Printf("[%s] %s: %s\n", mark, d.name, d.detail)
+		if d.fix != "" {
+			fmt.Printf("       fix: %s\n", d.fix)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d check(s) failed", failed)
+	}
+	return nil
+}
+
+// checkGit makes sure the git binary, used for fetching, pushing and exec, is
+// available
+func checkGit() diagnosis {
+	out, err := exec.Command("git", "--version").Output()
+	if err != nil {
+		return diagnosis{name: "git", detail: err.Error(), fix: "install git and make sure it is on the PATH", failed: true}
+	}
+	return diagnosis{name: "git", detail: strings.TrimSpace(string(out))}
+}
+
+// checkConfig validates every config file applying to the scan root
+func checkConfig(scanPath string) diagnosis {
+	problems := gori.ValidateConfig(scanPath)
+	if len(problems) > 0 {
+		return diagnosis{
+			name:   "config",
+			detail: fmt.Sprintf("%d problem(s), the first being %s", len(problems), problems[0]),
+			fix:    fmt.Sprintf("run gori config validate %s for all of them", scanPath),
+			failed: true,
+		}
+	}
+	return diagnosis{name: "config", detail: "no problems found"}
+}
+
+// checkCacheDir makes sure the prompt history and discovery cache can be
+// written
+func checkCacheDir() diagnosis {
+	cacheDir, err := gori.CacheDir()
+	if err == nil {
+		err = checkWritable(cacheDir)
+	}
+	if err != nil {
+		return diagnosis{name: "cache dir", detail: err.Error(), fix: "make $XDG_CACHE_HOME, or ~/.cache, writable", warning: true}
+	}
+	return diagnosis{name: "cache dir", detail: cacheDir + " is writable"}
+}
+
+// checkWritable creates dir if needed and a file in it
+func checkWritable(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, "doctor")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
+
+// checkTerminal reports whether visiting and dimmed output are available
+func checkTerminal() diagnosis {
+	if !readline.IsTerminal(int(os.Stdin.Fd())) || !readline.IsTerminal(int(os.Stdout.Fd())) {
+		return diagnosis{name: "terminal", detail: "not a terminal, visiting projects and dimmed output are unavailable", warning: true}
+	}
+	term := os.Getenv("TERM")
+	if term == "" || term == "dumb" {
+		return diagnosis{name: "terminal", detail: fmt.Sprintf("TERM is %q, colors may not show", term), fix: "set TERM, e.g. to xterm-256color", warning: true}
+	}
+	return diagnosis{name: "terminal", detail: "TERM=" + term}
+}
+
+// checkEmoji guesses from the locale whether the status emoji can be shown
+func checkEmoji() diagnosis {
+	locale := os.Getenv("LC_ALL")
+	if locale == "" {
+		locale = os.Getenv("LC_CTYPE")
+	}
+	if locale == "" {
+		locale = os.Getenv("LANG")
+	}
+	upper := strings.ToUpper(locale)
+	if !strings.Contains(upper, "UTF-8") && !strings.Contains(upper, "UTF8") {
+		return diagnosis{name: "emoji", detail: fmt.Sprintf("locale %q is not UTF-8, the status emoji may be garbled", locale), fix: "use a UTF-8 locale, e.g. LANG=en_US.UTF-8", warning: true}
+	}
+	return diagnosis{name: "emoji", detail: "locale " + locale}
+}
+
+// checkCredentials looks for a way for git to authenticate without prompting,
+// which gori fetch and push need as they run many git commands at once
+func checkCredentials() diagnosis {
+	var found []string
+	if out, err := exec.Command("git", "config", "--get", "credential.helper").Output(); err == nil {
+		found = append(found, "credential helper "+strings.TrimSpace(string(out)))
+	}
+	if sock := os.Getenv("SSH_AUTH_SOCK"); sock != "" {
+		if _, err := os.Stat(sock); err == nil {
+			found = append(found, "ssh agent")
+		}
+	}
+	if len(found) == 0 {
+		return diagnosis{
+			name:    "credentials",
+			detail:  "no git credential helper nor ssh agent, fetch and push may prompt for every repository",
+			fix:     "start an ssh agent or set one up with git config --global credential.helper",
+			warning: true,
+		}
+	}
+	return diagnosis{name: "credentials", detail: strings.Join(found, ", ")}
+}
+
+// checkCommand makes sure an external program gori can start is installed
+func checkCommand(name string, command func() ([]string, error)) diagnosis {
+	args, err := command()
+	if err != nil {
+		return diagnosis{name: name, detail: err.Error(), warning: true}
+	}
+	if _, err := exec.LookPath(args[0]); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return diagnosis{name: name, detail: args[0] + " is not on the PATH", fix: "install it or configure another one in gori.cue", warning: true}
+		}
+		return diagnosis{name: name, detail: err.Error(), warning: true}
+	}
+	return diagnosis{name: name, detail: strings.Join(args, " ")}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hansbogert/gori"
+)
+
+// explainProject tells why each of the project's checks fired, after its
+// snoozes: the changes making it dirty, and from backends which can tell, the
+// commits which are not upstreamed and the stashes
+func explainProject(backend Backend, project gori.ProjectStatus, changes string) []string {
+	var lines []string
+	if project.IsDirty {
+		lines = append(lines, tr("dirty, uncommitted changes:"))
+		for line := range strings.Lines(strings.TrimRight(changes, "\n")) {
+			lines = append(lines, "  "+strings.TrimRight(line, "\n"))
+		}
+	}
+
+	if len(project.DivergedTags) > 0 {
+		lines = append(lines, tr("tags pointing at another commit than on origin:"))
+		for _, tag := range project.DivergedTags {
+			lines = append(lines, fmt.Sprintf(tr("  %s: %s here, %s on origin"), tag.Name, tag.Local[:7], tag.Remote[:7]))
+		}
+	}
+
+	explainer, ok := backend.(explainingBackend)
+	if !ok {
+		return lines
+	}
+	if project.Diverged != nil {
+		lines = append(lines, fmt.Sprintf(tr("diverged from %s, %d commit(s) ahead and %d behind: rebase or merge needed"), project.Diverged.Upstream, project.Diverged.Ahead, project.Diverged.Behind))
+	}
+	if !project.Upstreamed {
+		lines = append(lines, explainer.ExplainUnpushed(project.Path)...)
+	}
+	if project.HasStash {
+		lines = append(lines, tr("stashes:"))
+		for _, stash := range explainer.ExplainStashes(project.Path) {
+			lines = append(lines, "  "+stash)
+		}
+	}
+	return lines
+}
+
+// printExplanation shows the explanation of the project, indented below its
+// status line
+func printExplanation(project gori.ProjectStatus, indent string) {
+	for _, line := range project.Explanation {
+		fmt.Println(indent + line)
+	}
+}
+
+// maxExplainedCommits is how many commits are listed at most, long running
+// branches would otherwise drown the rest
+const maxExplainedCommits = 10
+
+// explainCommits puts the line telling what the commits were compared with
+// above them, leaving out those beyond maxExplainedCommits
+func explainCommits(comparedWith string, commits []string) []string {
+	lines := []string{fmt.Sprintf(tr("not upstreamed, %d commit(s) not on %s:"), len(commits), comparedWith)}
+	for _, commit := range commits[:min(len(commits), maxExplainedCommits)] {
+		lines = append(lines, "  "+commit)
+	}
+	if len(commits) > maxExplainedCommits {
+		lines = append(lines, fmt.Sprintf(tr("  and %d more"), len(commits)-maxExplainedCommits))
+	}
+	return lines
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/spf13/cobra"
+
+	"github.com/hansbogert/gori"
+)
+
+// newFleetCmd builds the command reporting on every host of the fleet
+func newFleetCmd() *cobra.Command {
+	fleetCmd := &cobra.Command{
+		Use:   "fleet",
+		Short: "Scan every host configured in gori.cue and report them together, grouped by host",
+		RunE:  runFleet,
+		Args:  cobra.NoArgs,
+	}
+	fleetCmd.Flags().StringVar(&outputFormat, "format", "text", fmt.Sprintf("output format, one of %v", OutputFormats))
+	fleetCmd.Flags().StringVarP(&outputFile, "output", "o", "", "write the report in --format to this file instead of stdout, replacing it at once")
+	fleetCmd.Flags().BoolVar(&copyReport, "copy", false, "copy the report in --format to the clipboard as well, through the terminal over ssh")
+	_ = fleetCmd.RegisterFlagCompletionFunc("format", completeValues(OutputFormats))
+	return fleetCmd
+}
+
+func runFleet(cmd *cobra.Command, args []string) error {
+	if !slices.Contains(OutputFormats, outputFormat) {
+		return fmt.Errorf("unknown format %q, use one of %v", outputFormat, OutputFormats)
+	}
+	fleet := loadSettings().Fleet
+	if len(fleet) == 0 {
+		return fmt.Errorf("no fleet configured, list the hosts in gori.cue, e.g. fleet: [{name: \"nas\", host: \"me@nas:/srv/repos\"}]")
+	}
+	records, err := fleetRecords(fleet)
+	if err != nil {
+		return err
+	}
+	return writeReport(records, outputFormat)
+}
+
+// fleetRecords scans the hosts of the fleet concurrently, the records are
+// marked with the host's name and kept in the order of the fleet
+func fleetRecords(fleet []gori.FleetHost) ([]repoRecord, error) {
+	hosts := make([]remoteHost, len(fleet))
+	for i, member := range fleet {
+		host, err := parseRemoteHost(member.Host)
+		if err != nil {
+			return nil, fmt.Errorf("fleet member %s: %w", cmp.Or(member.Name, member.Host), err)
+		}
+		hosts[i] = host
+	}
+
+	results := make([][]repoRecord, len(fleet))
+	var wg sync.WaitGroup
+	for i, host := range hosts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			name := cmp.Or(fleet[i].Name, host.destination)
+			records, err := remoteRecords(host)
+			if err != nil {
+				records = []repoRecord{{Path: cmp.Or(host.path, "~"), Error: err.Error()}}
+			}
+			for j := range records {
+				records[j].Host = name
+			}
+			results[i] = records
+		}()
+	}
+	wg.Wait()
+	return slices.Concat(results...), nil
+}
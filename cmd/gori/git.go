@@ -0,0 +1,665 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+
+	"github.com/hansbogert/gori"
+)
+
+// gitBackend checks git repositories with go-git
+type gitBackend struct{}
+
+func (gitBackend) Name() string { return "git" }
+
+// Discover takes every directory, one which is not a git repository fails to
+// open
+func (gitBackend) Discover(repoPath string) bool { return true }
+
+func (gitBackend) Status(repoPath string) (bool, string, error) {
+	repo, err := gori.OpenRepo(repoPath)
+	if err != nil {
+		return false, "", fmt.Errorf("opening repo: %w", err)
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		return false, "", fmt.Errorf("getting worktree: %w", err)
+	}
+
+	status, err := wt.Status()
+	if err != nil {
+		return false, "", fmt.Errorf("getting repo status: %w", err)
+	}
+
+	// an uncommitted policy file should not make the repo dirty
+	if fs, ok := status[gori.RepoConfigFile]; ok && fs.Worktree == git.Untracked {
+		delete(status, gori.RepoConfigFile)
+	}
+	if err := ignoreSparse(repo, status); err != nil {
+		return false, "", fmt.Errorf("reading index: %w", err)
+	}
+	return !status.IsClean(), status.String(), nil
+}
+
+// ignoreSparse removes the files left out of a sparse checkout from status.
+// git marks them skip-worktree in the index, go-git does not heed that and
+// reports them as deleted.
+func ignoreSparse(repo *git.Repository, status git.Status) error {
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return err
+	}
+	for _, entry := range idx.Entries {
+		if !entry.SkipWorktree {
+			continue
+		}
+		if fs, ok := status[entry.Name]; ok && fs.Worktree == git.Deleted && fs.Staging == git.Unmodified {
+			delete(status, entry.Name)
+		}
+	}
+	return nil
+}
+
+func (gitBackend) UpstreamState(repoPath string) (bool, []string, error) {
+	repo, err := gori.OpenRepo(repoPath)
+	if err != nil {
+		return false, nil, fmt.Errorf("opening repo: %w", err)
+	}
+	upstreamed, problems := isUpstreamed(repo, repoPath)
+	return upstreamed, problems, nil
+}
+
+func (gitBackend) Stashes(repoPath string) (int, error) {
+	return countStashes(repoPath), nil
+}
+
+func (gitBackend) Details(repoPath string) (time.Time, string) {
+	repo, err := gori.OpenRepo(repoPath)
+	if err != nil {
+		return time.Time{}, ""
+	}
+	return lastCommitTime(repo), gori.RepositoryOriginURL(repo)
+}
+
+// LookUpHosting asks the hosting provider whether the repository is orphaned,
+// for the pull request of a branch which is not upstreamed and for the CI
+// outcome of HEAD, and the remote for its tags
+func (gitBackend) LookUpHosting(repoPath string, upstreamed bool, project *gori.ProjectStatus) []string {
+	if !detectOrphans && !pullRequests && !ciStatus && !checkTags {
+		return nil
+	}
+	repo, err := gori.OpenRepo(repoPath)
+	if err != nil {
+		return []string{fmt.Sprintf("opening repo: %s", err)}
+	}
+
+	var problems []string
+	if detectOrphans {
+		if err := detectOrphan(repo, project); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	if checkTags {
+		if err := detectDivergedTags(repo, project); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	if pullRequests && !project.Upstreamed {
+		if err := lookUpPullRequest(repo, project); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	// only a pushed HEAD can have been built by CI
+	if ciStatus && (upstreamed || project.PullRequest != nil) {
+		if err := lookUpCI(repo, project); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	return problems
+}
+
+// Divergence compares the checked out branch with its configured upstream, or
+// else its origin counterpart
+func (gitBackend) Divergence(repoPath string) (string, int, int) {
+	repo, err := gori.OpenRepo(repoPath)
+	if err != nil {
+		return "", 0, 0
+	}
+	head, err := repo.Head()
+	if err != nil || !head.Name().IsBranch() {
+		return "", 0, 0
+	}
+	branch := head.Name().Short()
+	upstream := plumbing.NewRemoteReferenceName("origin", branch)
+	if cfg, err := repo.Config(); err == nil {
+		if b, ok := cfg.Branches[branch]; ok && b.Remote != "" && b.Remote != "." && b.Merge.IsBranch() {
+			upstream = plumbing.NewRemoteReferenceName(b.Remote, b.Merge.Short())
+		}
+	}
+	remoteRef, err := repo.Reference(upstream, true)
+	if err != nil {
+		return "", 0, 0
+	}
+	local, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return "", 0, 0
+	}
+	remote, err := repo.CommitObject(remoteRef.Hash())
+	if err != nil {
+		return "", 0, 0
+	}
+	// a shallow history may end before the merge base, those which did not
+	// diverge are told apart for sure
+	for _, pair := range [][2]*object.Commit{{local, remote}, {remote, local}} {
+		if contained, err := isAncestor(repo, pair[0], pair[1]); contained || err != nil {
+			return upstream.Short(), 0, 0
+		}
+	}
+	ahead := unpushedCommits(local, []*object.Commit{remote})
+	behind := unpushedCommits(remote, []*object.Commit{local})
+	return upstream.Short(), len(ahead), len(behind)
+}
+
+// ExplainUnpushed lists the commits of the branch which are neither on its
+// origin counterpart nor on the mainish branch, the branches isUpstreamed
+// compares with
+func (gitBackend) ExplainUnpushed(repoPath string) []string {
+	repo, err := gori.OpenRepo(repoPath)
+	if err != nil {
+		return nil
+	}
+	head, err := repo.Head()
+	if err != nil || !head.Name().IsBranch() {
+		return []string{tr("not upstreamed, HEAD is not on a branch")}
+	}
+
+	branch := head.Name().Short()
+	names := []string{branch}
+	if mainish, err := getLikelyUpstreamMainishBranch(repo); err == nil && mainish != branch {
+		names = append(names, mainish)
+	}
+	var compared []string
+	var targets []*object.Commit
+	for _, name := range names {
+		ref, err := repo.Reference(plumbing.NewRemoteReferenceName("origin", name), true)
+		if err != nil {
+			continue
+		}
+		if commit, err := repo.CommitObject(ref.Hash()); err == nil {
+			compared = append(compared, "origin/"+name)
+			targets = append(targets, commit)
+		}
+	}
+	if len(targets) == 0 {
+		return []string{fmt.Sprintf(tr("not upstreamed, there is no origin/%s, origin/main or origin/master to compare with"), branch)}
+	}
+
+	headCommit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil
+	}
+	return explainCommits(strings.Join(compared, tr(" or ")), unpushedCommits(headCommit, targets))
+}
+
+// unpushedCommits walks the history of head down to where it meets one of the
+// targets, returning the commits it passed, newest first, as short hash and
+// subject. Commits missing from a shallow clone end the walk.
+func unpushedCommits(head *object.Commit, targets []*object.Commit) []string {
+	mergeBases := map[plumbing.Hash]bool{}
+	for _, target := range targets {
+		bases, _ := head.MergeBase(target)
+		for _, base := range bases {
+			mergeBases[base.Hash] = true
+		}
+	}
+
+	var commits []string
+	_ = object.NewCommitPreorderIter(head, mergeBases, nil).ForEach(func(c *object.Commit) error {
+		subject, _, _ := strings.Cut(c.Message, "\n")
+		commits = append(commits, c.Hash.String()[:7]+" "+subject)
+		return nil
+	})
+	return commits
+}
+
+// RecentCommits walks the history of HEAD until it reaches a commit older than
+// since, at most maxExplainedCommits of them
+func (gitBackend) RecentCommits(repoPath string, since time.Time) (string, []string) {
+	repo, err := gori.OpenRepo(repoPath)
+	if err != nil {
+		return "", nil
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return "", nil
+	}
+	headCommit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return head.Name().Short(), nil
+	}
+
+	var subjects []string
+	_ = object.NewCommitPreorderIter(headCommit, nil, nil).ForEach(func(c *object.Commit) error {
+		if c.Committer.When.Before(since) || len(subjects) == maxExplainedCommits {
+			return storer.ErrStop
+		}
+		subject, _, _ := strings.Cut(c.Message, "\n")
+		subjects = append(subjects, subject)
+		return nil
+	})
+	return head.Name().Short(), subjects
+}
+
+// ExplainStashes lists the stashes like git stash list, from the stash's
+// reflog
+func (gitBackend) ExplainStashes(repoPath string) []string {
+	reflog, err := os.ReadFile(filepath.Join(repoPath, ".git", "logs", "refs", "stash"))
+	if err != nil {
+		return nil
+	}
+	entries := strings.Split(strings.TrimSpace(string(reflog)), "\n")
+	var stashes []string
+	for i := range entries {
+		_, message, _ := strings.Cut(entries[len(entries)-1-i], "\t")
+		stashes = append(stashes, fmt.Sprintf("stash@{%d}: %s", i, message))
+	}
+	return stashes
+}
+
+// countStashes counts the stashed changes of the repository, the entries of
+// the stash's reflog. A stash without a reflog counts once.
+func countStashes(repoPath string) int {
+	stashPath := filepath.Join(repoPath, ".git", "refs", "stash")
+	if _, err := os.Stat(stashPath); err != nil {
+		return 0
+	}
+	reflog, err := os.ReadFile(filepath.Join(repoPath, ".git", "logs", "refs", "stash"))
+	if err != nil {
+		return 1
+	}
+	return max(1, strings.Count(string(reflog), "\n"))
+}
+
+// isUpstreamed determines if a current checkout is up to date with its origin
+// counterpart, or is part of a mainish branch. It also returns the problems
+// which kept it from telling for sure.
+func isUpstreamed(repo *git.Repository, repoPath string) (bool, []string) {
+	return checkUpstream(repo, repoPath, isBranchUpstreamed)
+}
+
+// checkUpstream is isUpstreamed comparing the branches with branchUpstreamed
+func checkUpstream(repo *git.Repository, repoPath string, branchUpstreamed func(repo *git.Repository, localBranchName, remoteBranchName string) (bool, error)) (bool, []string) {
+	// Get the current branch
+	ref, err := repo.Head()
+	if err != nil {
+		return false, []string{fmt.Sprintf("getting HEAD: %s", err)}
+	}
+
+	// TODO, we should fallback to see if the commit itself is upstreamed
+	if ref.Name().Short() == "HEAD" {
+		return false, []string{"local checkout does not have branch name"}
+	}
+
+	branch := ref.Name().Short()
+	log := slog.With("repo", repoPath, "branch", branch)
+
+	var problems []string
+	// Check if the branch is upstreamed
+	isUpstreamed, err := branchUpstreamed(repo, branch, branch)
+	// the mainish branch might still tell for sure
+	approximate := errors.Is(err, errShallowApproximate)
+	if err != nil && !approximate && err != plumbing.ErrReferenceNotFound {
+		// +state nobranchupstream
+		problems = append(problems, fmt.Sprintf("checking if branch itself is upstreamed: %v", err))
+	}
+	log.Debug("compared with its origin counterpart", "upstreamed", isUpstreamed, "err", err)
+	if isUpstreamed {
+		return true, problems
+	}
+
+	// Check if the branch is upstreamed with main
+	mainish, mainishErr := getLikelyUpstreamMainishBranch(repo)
+	if mainishErr != nil && pullRequests {
+		// the hosting provider knows the default branch by any name
+		if defaultBranch, err := hostedDefaultBranch(repo); err == nil {
+			mainish, mainishErr = defaultBranch, nil
+		}
+	}
+	log.Debug("picked the mainish branch", "mainish", mainish, "err", mainishErr)
+
+	if mainishErr != nil {
+		if approximate {
+			problems = append(problems, errShallowApproximate.Error())
+		}
+		return false, append(problems, fmt.Sprintf("could not determine upstream branch: %v", mainishErr))
+	}
+
+	isUpstreamed, err = branchUpstreamed(repo, branch, mainish)
+	log.Debug("compared with the mainish branch", "mainish", mainish, "upstreamed", isUpstreamed, "err", err)
+	if errors.Is(err, errShallowApproximate) {
+		return false, append(problems, err.Error())
+	}
+	if err != nil && err != plumbing.ErrReferenceNotFound {
+		return false, append(problems, fmt.Sprintf("checking if branch is upstreamed into main: %v", err))
+	}
+
+	if err == plumbing.ErrReferenceNotFound {
+		return false, append(problems, fmt.Sprintf("origin does not have %s branch", mainish))
+	}
+	if !isUpstreamed && approximate {
+		problems = append(problems, errShallowApproximate.Error())
+	}
+
+	return isUpstreamed, problems
+}
+
+// getLikelyUpstreamMainishBranch gets the likely upstream mainish branch, e.g.,
+// main or master
+func getLikelyUpstreamMainishBranch(repo *git.Repository) (string, error) {
+	var mainish string
+	refIter, err := repo.References()
+	if err != nil {
+		return "", fmt.Errorf("could not get references: %w", err)
+	}
+	refIter.ForEach(func(r *plumbing.Reference) error {
+		if r.Name().IsRemote() {
+			if r.Name().Short() == "origin/master" {
+				mainish = "master"
+			}
+
+			if r.Name().Short() == "origin/main" {
+				mainish = "main"
+			}
+		}
+		return nil
+	})
+
+	if mainish == "" {
+		return mainish, fmt.Errorf("neither main nor master branch exists")
+	}
+
+	return mainish, nil
+}
+
+// isBranchUpstreamed checks if the given branch is upstreamed in the origin repo
+func isBranchUpstreamed(repo *git.Repository, localBranchName, remoteBranchName string) (bool, error) {
+	// Get the local branch reference
+	localRef, err := repo.Reference(plumbing.NewBranchReferenceName(localBranchName), true)
+	if err != nil {
+		return false, fmt.Errorf("could not get local branch: %w", err)
+	}
+
+	lObject, err := repo.CommitObject(localRef.Hash())
+	if err != nil {
+		return false, err
+	}
+
+	// Get the reference to the remote branch
+	remoteRef, err := repo.Reference(plumbing.NewRemoteReferenceName("origin", remoteBranchName), true)
+
+	if err != nil {
+		return false, err
+	}
+
+	rObject, err := repo.CommitObject(remoteRef.Hash())
+
+	if err != nil {
+		return false, fmt.Errorf(`cannot get remoteRef, \"origin/%s\" by hash: %w`, remoteBranchName, err)
+	}
+
+	return isAncestor(repo, lObject, rObject)
+}
+
+// errShallowApproximate is returned by isAncestor when the answer is no, but
+// the history of a shallow clone ended before it could tell for sure
+var errShallowApproximate = errors.New("shallow clone, upstream check approximate")
+
+// isAncestor reports whether commit is an ancestor of other. In a shallow clone
+// the history is walked as far as it goes instead of failing on the commits
+// left out, so a commit which is not found might still be an ancestor further
+// back: then errShallowApproximate is returned along with false.
+func isAncestor(repo *git.Repository, commit, other *object.Commit) (bool, error) {
+	shallow, err := repo.Storer.Shallow()
+	if err != nil || len(shallow) == 0 {
+		return commit.IsAncestor(other)
+	}
+
+	seen := map[plumbing.Hash]bool{}
+	pending := []plumbing.Hash{other.Hash}
+	truncated := false
+	for len(pending) > 0 {
+		hash := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if seen[hash] {
+			continue
+		}
+		seen[hash] = true
+		if hash == commit.Hash {
+			return true, nil
+		}
+		c, err := repo.CommitObject(hash)
+		if errors.Is(err, plumbing.ErrObjectNotFound) {
+			truncated = true
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		pending = append(pending, c.ParentHashes...)
+	}
+	if truncated {
+		return false, errShallowApproximate
+	}
+	return false, nil
+}
+
+// branchPush is a local branch which can be pushed to its upstream
+type branchPush struct {
+	branch string
+	remote string
+	merge  string
+}
+
+func (b branchPush) String() string {
+	return fmt.Sprintf("%s -> %s/%s", b.branch, b.remote, b.merge)
+}
+
+// aheadBranches returns the local branches which are strictly ahead of their
+// upstream, i.e. pushing them is a fast-forward
+func aheadBranches(repo *git.Repository) ([]branchPush, error) {
+	cfg, err := repo.Config()
+	if err != nil {
+		return nil, fmt.Errorf("reading config: %w", err)
+	}
+
+	var pushes []branchPush
+	for _, name := range slices.Sorted(maps.Keys(cfg.Branches)) {
+		branch := cfg.Branches[name]
+		if branch.Remote == "" || branch.Remote == "." || !branch.Merge.IsBranch() {
+			continue
+		}
+		localRef, err := repo.Reference(plumbing.NewBranchReferenceName(name), true)
+		if err != nil {
+			continue
+		}
+		remoteRef, err := repo.Reference(plumbing.NewRemoteReferenceName(branch.Remote, branch.Merge.Short()), true)
+		if err != nil || remoteRef.Hash() == localRef.Hash() {
+			continue
+		}
+
+		local, err := repo.CommitObject(localRef.Hash())
+		if err != nil {
+			return nil, fmt.Errorf("getting commit of %s: %w", name, err)
+		}
+		remote, err := repo.CommitObject(remoteRef.Hash())
+		if err != nil {
+			return nil, fmt.Errorf("getting commit of %s/%s: %w", branch.Remote, branch.Merge.Short(), err)
+		}
+		ahead, err := isAncestor(repo, remote, local)
+		if err != nil && !errors.Is(err, errShallowApproximate) {
+			return nil, fmt.Errorf("comparing %s with its upstream: %w", name, err)
+		}
+		if ahead {
+			pushes = append(pushes, branchPush{branch: name, remote: branch.Remote, merge: branch.Merge.Short()})
+		}
+	}
+	return pushes, nil
+}
+
+// prunableBranch is a local branch which can be deleted, and why
+type prunableBranch struct {
+	branch string
+	reason string
+}
+
+func (b prunableBranch) String() string {
+	return fmt.Sprintf("%s (%s)", b.branch, b.reason)
+}
+
+// prunableBranches returns the local branches which can be deleted: those
+// merged into the mainish branch of origin, squashed or rebased as well, and
+// those whose upstream is gone. The branch checked out and the mainish one are
+// kept.
+func prunableBranches(repo *git.Repository, repoPath string) ([]prunableBranch, error) {
+	cfg, err := repo.Config()
+	if err != nil {
+		return nil, fmt.Errorf("reading config: %w", err)
+	}
+	current := ""
+	if head, err := repo.Head(); err == nil && head.Name().IsBranch() {
+		current = head.Name().Short()
+	}
+	var target *object.Commit
+	mainish, err := getLikelyUpstreamMainishBranch(repo)
+	if err == nil {
+		if ref, err := repo.Reference(plumbing.NewRemoteReferenceName("origin", mainish), true); err == nil {
+			target, _ = repo.CommitObject(ref.Hash())
+		}
+	}
+
+	branches, err := repo.Branches()
+	if err != nil {
+		return nil, fmt.Errorf("listing branches: %w", err)
+	}
+	var names []string
+	_ = branches.ForEach(func(ref *plumbing.Reference) error {
+		names = append(names, ref.Name().Short())
+		return nil
+	})
+	slices.Sort(names)
+
+	var prunable []prunableBranch
+	for _, name := range names {
+		if name == current || name == mainish {
+			continue
+		}
+		if target != nil {
+			ref, err := repo.Reference(plumbing.NewBranchReferenceName(name), true)
+			if err != nil {
+				continue
+			}
+			local, err := repo.CommitObject(ref.Hash())
+			if err != nil {
+				return nil, fmt.Errorf("getting commit of %s: %w", name, err)
+			}
+			into := "origin/" + mainish
+			merged, err := isAncestor(repo, local, target)
+			if err != nil && !errors.Is(err, errShallowApproximate) {
+				return nil, fmt.Errorf("comparing %s with %s: %w", name, into, err)
+			}
+			if merged {
+				prunable = append(prunable, prunableBranch{name, "merged into " + into})
+				continue
+			}
+			if how := mergedByPatch(repoPath, name, into); how != "" {
+				prunable = append(prunable, prunableBranch{name, how + " " + into})
+				continue
+			}
+		}
+		branch, ok := cfg.Branches[name]
+		if !ok || branch.Remote == "" || branch.Remote == "." || !branch.Merge.IsBranch() {
+			continue
+		}
+		upstream := plumbing.NewRemoteReferenceName(branch.Remote, branch.Merge.Short())
+		if _, err := repo.Reference(upstream, true); errors.Is(err, plumbing.ErrReferenceNotFound) {
+			prunable = append(prunable, prunableBranch{name, "upstream " + upstream.Short() + " is gone"})
+		}
+	}
+	return prunable, nil
+}
+
+// mergedByPatch tells how the changes of branch made it into target without
+// its commits: "rebased onto" when each of its commits has an equivalent in
+// target, "squashed into" when all of them together have, or "" when they did
+// not. It only reads the repository, so it is safe for --dry-run and status.
+// go-git cannot compare patches, so the git command does.
+func mergedByPatch(repoPath, branch, target string) string {
+	if out, err := gitOutput(repoPath, "cherry", target, branch); err == nil && out != "" && !strings.Contains("\n"+out, "\n+") {
+		return "rebased onto"
+	}
+	base, err := gitOutput(repoPath, "merge-base", target, branch)
+	if err != nil {
+		return ""
+	}
+	base = strings.TrimSpace(base)
+
+	// the changes of the whole branch at once, like a squash merge of it has
+	squashed, err := gitOutput(repoPath, "diff", "--no-color", "--no-ext-diff", base, branch)
+	if err != nil || squashed == "" {
+		return ""
+	}
+	squashedIDs := patchIDs(repoPath, squashed)
+	if len(squashedIDs) == 0 {
+		return ""
+	}
+	targetPatches, err := gitOutput(repoPath, "log", "-p", "--no-merges", "--no-color", "--no-ext-diff", base+".."+target)
+	if err != nil {
+		return ""
+	}
+	if slices.Contains(patchIDs(repoPath, targetPatches), squashedIDs[0]) {
+		return "squashed into"
+	}
+	return ""
+}
+
+// patchIDs returns the stable patch ids of the patches, in order
+func patchIDs(repoPath, patches string) []string {
+	out, err := gitOutputInput(repoPath, nil, patches, "patch-id", "--stable")
+	if err != nil {
+		return nil
+	}
+	var ids []string
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		if id, _, ok := strings.Cut(line, " "); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// lastCommitTime returns the commit time of HEAD, or the zero time if it
+// cannot be determined
+func lastCommitTime(repo *git.Repository) time.Time {
+	ref, err := repo.Head()
+	if err != nil {
+		return time.Time{}
+	}
+	commit, err := repo.CommitObject(ref.Hash())
+	if err != nil {
+		return time.Time{}
+	}
+	return commit.Committer.When
+}
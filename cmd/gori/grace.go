@@ -0,0 +1,52 @@
+package main
+
+import (
+	"cmp"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/hansbogert/gori"
+)
+
+var dirtyGrace string
+
+// dirtyGracePeriod is how long a working directory has to be dirty before it
+// is reported, from --dirty-grace or dirty_grace in gori.cue, 0 for none. An
+// invalid one is warned about once.
+var dirtyGracePeriod = sync.OnceValue(func() time.Duration {
+	value := cmp.Or(dirtyGrace, loadSettings().DirtyGrace)
+	if value == "" {
+		return 0
+	}
+	grace, err := gori.ParseSnoozeDuration(value)
+	if err != nil {
+		slog.Warn("invalid dirty grace period, reporting every dirty working directory", "err", err)
+		return 0
+	}
+	return grace
+})
+
+// dirtyWithinGrace reports whether every change of the working directory was
+// made within the grace period, going by the modification time of the changed
+// files. Deleted files do not tell, with only those it is not.
+func dirtyWithinGrace(repoPath string, changes string) bool {
+	grace := dirtyGracePeriod()
+	if grace == 0 {
+		return false
+	}
+	known := false
+	for _, file := range changedFiles(changes) {
+		info, err := os.Stat(filepath.Join(repoPath, file))
+		if err != nil {
+			continue
+		}
+		if time.Since(info.ModTime()) >= grace {
+			return false
+		}
+		known = true
+	}
+	return known
+}
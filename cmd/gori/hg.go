@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// hgDir marks a Mercurial repository
+const hgDir = ".hg"
+
+// hgUnpushed selects the changesets not pushed to a publishing repository,
+// which turns them public, without asking the remote
+const hgUnpushed = "not public()"
+
+// hgBackend checks Mercurial repositories with hg
+type hgBackend struct{}
+
+func (hgBackend) Name() string { return "hg" }
+
+func (hgBackend) Discover(repoPath string) bool {
+	info, err := os.Stat(filepath.Join(repoPath, hgDir))
+	return err == nil && info.IsDir()
+}
+
+func (hgBackend) Status(repoPath string) (bool, string, error) {
+	if _, err := exec.LookPath("hg"); err != nil {
+		return false, "", errors.New("Mercurial repository, but hg is not installed")
+	}
+	status, err := hg(repoPath, "status")
+	if err != nil {
+		return false, "", err
+	}
+	return strings.TrimSpace(status) != "", status, nil
+}
+
+func (hgBackend) UpstreamState(repoPath string) (bool, []string, error) {
+	unpushed, err := hg(repoPath, "log", "-r", hgUnpushed, "-T", "{node|short}\n")
+	if err != nil {
+		return false, nil, err
+	}
+	return strings.TrimSpace(unpushed) == "", nil, nil
+}
+
+// Stashes counts the shelved changes, Mercurial's stashes. Each shelve is
+// kept in a few files named after it.
+func (hgBackend) Stashes(repoPath string) (int, error) {
+	entries, err := os.ReadDir(filepath.Join(repoPath, hgDir, "shelved"))
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	shelves := map[string]bool{}
+	for _, entry := range entries {
+		shelves[strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))] = true
+	}
+	return len(shelves), err
+}
+
+// ExplainUnpushed lists the changesets which are not public yet
+func (hgBackend) ExplainUnpushed(repoPath string) []string {
+	out, err := hg(repoPath, "log", "-r", hgUnpushed, "-T", "{node|short} {desc|firstline}\n")
+	if err != nil {
+		return nil
+	}
+	return explainCommits(tr("a publishing repository"), strings.Split(strings.TrimSpace(out), "\n"))
+}
+
+// ExplainStashes lists the shelves, newest first like hg shelve --list
+func (hgBackend) ExplainStashes(repoPath string) []string {
+	out, err := hg(repoPath, "shelve", "--list")
+	if err != nil {
+		return nil
+	}
+	return strings.Split(strings.TrimSpace(out), "\n")
+}
+
+// Details reads the commit time of the working directory's parent and the
+// default path, Mercurial's origin
+func (hgBackend) Details(repoPath string) (time.Time, string) {
+	var lastCommit time.Time
+	if out, err := hg(repoPath, "log", "-r", ".", "-T", "{date|hgdate}"); err == nil {
+		seconds, _, _ := strings.Cut(strings.TrimSpace(out), " ")
+		if unix, err := strconv.ParseInt(seconds, 10, 64); err == nil && unix != 0 {
+			lastCommit = time.Unix(unix, 0)
+		}
+	}
+	remoteURL, _ := hg(repoPath, "paths", "default")
+	return lastCommit, strings.TrimSpace(remoteURL)
+}
+
+// hg runs an hg command in the repository and returns its output, HGPLAIN
+// keeps the user's configuration from changing it
+func hg(repoPath string, args ...string) (string, error) {
+	cmd := exec.Command("hg", append([]string{"--repository", repoPath, "--noninteractive"}, args...)...)
+	cmd.Env = append(os.Environ(), "HGPLAIN=1")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("hg %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return string(out), nil
+}
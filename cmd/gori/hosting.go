@@ -0,0 +1,177 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"time"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+
+	"github.com/hansbogert/gori"
+)
+
+var pullRequests bool
+var detectOrphans bool
+var ciStatus bool
+
+// hostings are the hosting provider clients by host, shared by the workers
+var hostings = struct {
+	sync.Mutex
+	byHost map[string]gori.Hosting
+}{byHost: map[string]gori.Hosting{}}
+
+// hostingClient makes the requests to the hosting APIs, caching the answers
+// in the cache dir when possible
+var hostingClient = sync.OnceValue(func() *http.Client {
+	client := &http.Client{Timeout: 10 * time.Second}
+	ttl, err := gori.ParseSnoozeDuration(cmp.Or(loadSettings().HostingCacheTTL, "15m"))
+	if err != nil {
+		slog.Warn("invalid hosting_cache_ttl, not caching", "err", err)
+		return client
+	}
+	cacheDir, err := gori.CacheDir()
+	if err != nil {
+		return client
+	}
+	client.Transport = &gori.CachingTransport{Dir: filepath.Join(cacheDir, "hosting"), TTL: ttl}
+	return client
+})
+
+// hostingFor returns the client of the hosting provider at host, nil when
+// gori does not know which software runs there
+func hostingFor(host string) (gori.Hosting, error) {
+	hostings.Lock()
+	defer hostings.Unlock()
+	if hosting, ok := hostings.byHost[host]; ok {
+		return hosting, nil
+	}
+	hosting, ok, err := gori.NewHosting(host, loadSettings().Hosts, hostingClient())
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		slog.Debug("unknown hosting provider", "host", host)
+	}
+	hostings.byHost[host] = hosting
+	return hosting, nil
+}
+
+// hostedDefaultBranch asks the hosting provider of origin for its default
+// branch, which must have been fetched
+func hostedDefaultBranch(repo *git.Repository) (string, error) {
+	host, path, ok := gori.HostedRepo(gori.RepositoryOriginURL(repo))
+	if !ok {
+		return "", fmt.Errorf("origin is not hosted")
+	}
+	hosting, err := hostingFor(host)
+	if err != nil {
+		return "", err
+	}
+	if hosting == nil {
+		return "", fmt.Errorf("unknown hosting provider %s", host)
+	}
+	hosted, err := hosting.Repository(path)
+	if err != nil {
+		return "", err
+	}
+	if _, err := repo.Reference(plumbing.NewRemoteReferenceName("origin", hosted.DefaultBranch), true); err != nil {
+		return "", fmt.Errorf("origin/%s: %w", hosted.DefaultBranch, err)
+	}
+	return hosted.DefaultBranch, nil
+}
+
+// lookUpPullRequest finds the pull request of the checked out branch of a
+// project on a known hosting provider. An open one turns a not upstreamed
+// branch into one in review, a merged one makes it upstreamed.
+func lookUpPullRequest(repo *git.Repository, project *gori.ProjectStatus) error {
+	host, path, ok := gori.HostedRepo(project.RemoteURL)
+	if !ok {
+		return nil
+	}
+	hosting, err := hostingFor(host)
+	if err != nil || hosting == nil {
+		return err
+	}
+	ref, err := repo.Head()
+	if err != nil || !ref.Name().IsBranch() {
+		return nil
+	}
+	pr, err := hosting.PullRequest(path, ref.Name().Short(), ref.Hash().String())
+	if err != nil {
+		return err
+	}
+	project.PullRequest = pr
+	if pr != nil && pr.Merged {
+		slog.Debug("pull request merged", "repo", project.Path, "pr", pr.String())
+		project.Upstreamed = true
+	}
+	return nil
+}
+
+// detectOrphan finds out whether the origin of a project still exists and is
+// not archived, by asking its hosting provider or, for other hosts, by
+// listing the remote's references
+func detectOrphan(repo *git.Repository, project *gori.ProjectStatus) error {
+	if project.RemoteURL == "" {
+		return nil
+	}
+	if host, path, ok := gori.HostedRepo(project.RemoteURL); ok {
+		hosting, err := hostingFor(host)
+		if err != nil {
+			return err
+		}
+		if hosting != nil {
+			hosted, err := hosting.Repository(path)
+			switch {
+			case errors.Is(err, gori.ErrRepoNotFound):
+				project.Orphaned = gori.OrphanDeleted
+			case errors.Is(err, gori.ErrNotFoundWithoutToken):
+				return fmt.Errorf("unknown whether origin was deleted: %w", err)
+			case err != nil:
+				return err
+			case hosted.Archived:
+				project.Orphaned = gori.OrphanArchived
+			}
+			return nil
+		}
+	}
+
+	remote, err := repo.Remote("origin")
+	if err != nil {
+		return nil
+	}
+	_, err = remote.List(&git.ListOptions{})
+	switch {
+	case errors.Is(err, transport.ErrRepositoryNotFound):
+		project.Orphaned = gori.OrphanDeleted
+	case err != nil && !errors.Is(err, transport.ErrEmptyRemoteRepository):
+		return fmt.Errorf("listing origin: %w", err)
+	}
+	return nil
+}
+
+// lookUpCI finds the outcome of the CI of HEAD of a project on a known hosting
+// provider
+func lookUpCI(repo *git.Repository, project *gori.ProjectStatus) error {
+	host, path, ok := gori.HostedRepo(project.RemoteURL)
+	if !ok {
+		return nil
+	}
+	hosting, err := hostingFor(host)
+	if err != nil || hosting == nil {
+		return err
+	}
+	ref, err := repo.Head()
+	if err != nil {
+		return nil
+	}
+	project.CI, err = hosting.CIStatus(path, ref.Hash().String())
+	return err
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/chzyer/readline"
+
+	"github.com/hansbogert/gori"
+)
+
+var hyperlinks bool
+
+// hyperlinkTarget is what repository names link to, "dir" or "remote"
+var hyperlinkTarget string
+
+// supportsHyperlinks reports whether stdout is a terminal known to understand
+// OSC 8 hyperlinks. Others would show the escape sequences, so they are left
+// out unless asked for.
+func supportsHyperlinks() bool {
+	if !readline.IsTerminal(int(os.Stdout.Fd())) {
+		return false
+	}
+	if slices.Contains([]string{"iTerm.app", "WezTerm", "vscode", "ghostty", "Hyper", "Tabby"}, os.Getenv("TERM_PROGRAM")) {
+		return true
+	}
+	if slices.Contains([]string{"xterm-kitty", "xterm-ghostty", "alacritty", "foot", "wezterm"}, os.Getenv("TERM")) {
+		return true
+	}
+	// GNOME Terminal, Tilix and the other terminals built on VTE 0.50 or
+	// later, Konsole and Windows Terminal
+	if vte, err := strconv.Atoi(os.Getenv("VTE_VERSION")); err == nil && vte >= 5000 {
+		return true
+	}
+	return os.Getenv("KONSOLE_VERSION") != "" || os.Getenv("WT_SESSION") != ""
+}
+
+// hyperlink makes the name a link to the project with OSC 8, when --hyperlinks
+// is in effect
+func hyperlink(name string, project gori.ProjectStatus) string {
+	if !hyperlinks {
+		return name
+	}
+	target := projectURL(project)
+	if target == "" {
+		return name
+	}
+	return "\x1b]8;;" + target + "\x1b\\" + name + "\x1b]8;;\x1b\\"
+}
+
+// projectURL is the web page of the project's origin for the remote target,
+// and otherwise its directory, as a file URL naming this host so terminals of
+// other machines do not open it
+func projectURL(project gori.ProjectStatus) string {
+	if hyperlinkTarget == "remote" {
+		if host, path, ok := gori.HostedRepo(project.RemoteURL); ok {
+			return "https://" + host + "/" + path
+		}
+	}
+	abs, err := filepath.Abs(project.Path)
+	if err != nil {
+		return ""
+	}
+	path := filepath.ToSlash(abs)
+	if !strings.HasPrefix(path, "/") {
+		// C:/repos on Windows
+		path = "/" + path
+	}
+	hostname, _ := os.Hostname()
+	return (&url.URL{Scheme: "file", Host: hostname, Path: path}).String()
+}
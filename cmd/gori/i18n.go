@@ -0,0 +1,35 @@
+package main
+
+import (
+	"cmp"
+	"os"
+	"strings"
+)
+
+// translations hold the messages in languages other than English, keyed by
+// the English message. Messages without a translation are shown in English.
+var translations = map[string]map[string]string{
+	"nl": dutch,
+}
+
+// language is the language messages are shown in
+var language = messageLanguage()
+
+// messageLanguage returns the language of the locale set by LC_ALL,
+// LC_MESSAGES or LANG, in that order like gettext, e.g. "nl" for nl_BE.UTF-8
+func messageLanguage() string {
+	locale := cmp.Or(os.Getenv("LC_ALL"), os.Getenv("LC_MESSAGES"), os.Getenv("LANG"))
+	lang, _, _ := strings.Cut(locale, ".")
+	lang, _, _ = strings.Cut(lang, "@")
+	lang, _, _ = strings.Cut(lang, "_")
+	return strings.ToLower(lang)
+}
+
+// tr translates the message into the user's language. A format string is
+// translated as a whole, its verbs stay in the same order.
+func tr(message string) string {
+	if translated, ok := translations[language][message]; ok {
+		return translated
+	}
+	return message
+}
@@ -0,0 +1,108 @@
+package main
+
+// dutch are the Dutch translations of the messages
+var dutch = map[string]string{
+	// legend
+	"Legend:":                 "Legenda:",
+	"Emoji Legend:":           "Emoji-legenda:",
+	"Dirty working directory": "Gewijzigde werkmap",
+	"Stashed changes":         "Wijzigingen in de stash",
+	"Not upstreamed":          "Niet upstream",
+	"In review":               "In review",
+	"CI of HEAD passed, failed or is still running":      "CI van HEAD geslaagd, mislukt of nog bezig",
+	"Tags pointing at another commit than on origin":     "Tags die naar een andere commit wijzen dan op origin",
+	"Diverged from its upstream, rebase or merge needed": "Uiteengelopen met de upstream, rebase of merge nodig",
+	"Orphaned clone, its origin was deleted or archived": "Verweesde clone, de origin is verwijderd of gearchiveerd",
+	"Snoozed": "Gesnoozed",
+
+	// --accessible
+	"dirty":                          "gewijzigd",
+	"stashed changes":                "wijzigingen in de stash",
+	"not upstreamed":                 "niet upstream",
+	"in review":                      "in review",
+	"tags differ from origin":        "tags wijken af van origin",
+	"diverged":                       "uiteengelopen",
+	"orphaned":                       "verweesd",
+	"snoozed":                        "gesnoozed",
+	"passed":                         "geslaagd",
+	"failed":                         "mislukt",
+	"running":                        "bezig",
+	"%d stashes":                     "%d stashes",
+	"No repository needs attention.": "Geen enkele repository vraagt aandacht.",
+	"%d repository(s) need attention: %d dirty, %d with stashed changes, %d not upstreamed.\n": "%d repository('s) vragen aandacht: %d gewijzigd, %d met wijzigingen in de stash, %d niet upstream.\n",
+	"(last commit %s ago)": "(laatste commit %s geleden)",
+	"(no remote)":          "(geen remote)",
+	"%s, %d need attention: %d dirty, %d with stashed changes, %d not upstreamed\n": "%s, %d vragen aandacht: %d gewijzigd, %d met wijzigingen in de stash, %d niet upstream\n",
+	"%d repository(s) with problems, listed after the results.\n":                   "%d repository('s) met problemen, vermeld na de resultaten.\n",
+
+	// --explain
+	"dirty, uncommitted changes:":                                                "gewijzigd, niet gecommitte wijzigingen:",
+	"tags pointing at another commit than on origin:":                            "tags die naar een andere commit wijzen dan op origin:",
+	"  %s: %s here, %s on origin":                                                "  %s: %s hier, %s op origin",
+	"diverged from %s, %d commit(s) ahead and %d behind: rebase or merge needed": "uiteengelopen met %s, %d commit(s) voor en %d achter: rebase of merge nodig",
+	"not upstreamed, %d commit(s) not on %s:":                                    "niet upstream, %d commit(s) niet op %s:",
+	"  and %d more": "  en nog %d",
+	"not upstreamed, HEAD is not on a branch": "niet upstream, HEAD staat niet op een branch",
+	" or ":                    " of ",
+	"a publishing repository": "een publicerende repository",
+	"not upstreamed, there is no origin/%s, origin/main or origin/master to compare with": "niet upstream, er is geen origin/%s, origin/main of origin/master om mee te vergelijken",
+
+	// status
+	"Problems:":                       "Problemen:",
+	"Snoozes expiring soon:":          "Snoozes die binnenkort verlopen:",
+	"  %s: %s snooze expires in %s\n": "  %s: snooze van %s verloopt over %s\n",
+	"Error: %s\n":                     "Fout: %s\n",
+
+	// visit
+	"Nothing to visit.": "Niets te bezoeken.",
+	"(s)tatus, (p)rint results, (i)gnore, ignore (a)ll, (u)nsnooze, ac(k), (d)one, (w)ip, (n)ext, (e)xecute shell, (o)pen, (g)it-ui": "(s)tatus, resultaten (p)rinten, (i)gnoreren, (a)lles negeren, (u)nsnoozen, er(k)ennen, klaar (d), (w)ip, (n)aar volgende, shell uitvoeren (e), (o)penen, (g)it-ui",
+	", (q)uit: ":                          ", stoppen (q): ",
+	" (partially snoozed)":                " (deels gesnoozed)",
+	"\nProject %d/%d: %s%s\n\n":           "\nProject %d/%d: %s%s\n\n",
+	"\nProject %d/%d: %s is clean now.\n": "\nProject %d/%d: %s is nu schoon.\n",
+	"Invalid command.":                    "Ongeldige opdracht.",
+	"Invalid selection:":                  "Ongeldige selectie:",
+	"Select projects to visit (e.g. 1,3-5), empty for all: ":             "Kies de projecten om te bezoeken (bv. 1,3-5), leeg voor alle: ",
+	"Usage: %s <duration> [check], or set snooze_duration in gori.cue\n": "Gebruik: %s <duur> [check], of stel snooze_duration in gori.cue in\n",
+	"Snoozed %s of %d project(s) for %s.\n":                              "%s van %d project(en) gesnoozed voor %s.\n",
+	"Error: could not start prompt: %s\n":                                "Fout: kan de prompt niet starten: %s\n",
+	"Error: could not find shell executable '%s': %v. Aborting.\n":       "Fout: kan de shell '%s' niet vinden: %v. Afgebroken.\n",
+	"Error: SHELL environment variable points to a non-standard location: %s. For security, only shells in %v are allowed. Aborting.\n": "Fout: de omgevingsvariabele SHELL wijst naar een ongebruikelijke locatie: %s. Voor de veiligheid zijn alleen shells in %v toegestaan. Afgebroken.\n",
+	"Error starting subshell: %s\n":                             "Fout bij het starten van de subshell: %s\n",
+	"Error: could not find pager executable '%s': %v.\n":        "Fout: kan de pager '%s' niet vinden: %v.\n",
+	"Error starting pager: %s\n":                                "Fout bij het starten van de pager: %s\n",
+	"Error: could not find %s executable '%s': %v. Aborting.\n": "Fout: kan %s '%s' niet vinden: %v. Afgebroken.\n",
+	"Error starting %s: %s\n":                                   "Fout bij het starten van %s: %s\n",
+
+	// report
+	"## Work in progress, last %d day(s)\n\n": "## Onderhanden werk, afgelopen %d dag(en)\n\n",
+	"Nothing in progress.":                    "Niets onderhanden.",
+	"%d uncommitted file(s)":                  "%d niet gecommit(te) bestand(en)",
+
+	// snooze
+	"Nothing is snoozed.":         "Er is niets gesnoozed.",
+	"Nothing to snooze.":          "Niets te snoozen.",
+	"Snoozed %s of %s for %s.\n":  "%s van %s gesnoozed voor %s.\n",
+	"Removed %d snooze(s).\n":     "%d snooze(s) verwijderd.\n",
+	"Pruned %d snooze(s).\n":      "%d snooze(s) opgeruimd.\n",
+	"Pruned":                      "Opgeruimd",
+	"No snooze changes recorded.": "Geen snoozewijzigingen vastgelegd.",
+	"Policy violations:":          "Beleidsschendingen:",
+	"warning":                     "waarschuwing",
+	"error":                       "fout",
+	"%d policy violation(s) of severity error":                              "%d beleidsschending(en) met ernst fout",
+	"%d repository(s) failing a check of severity error":                    "%d repository('s) die een controle met ernst fout niet doorstaan",
+	"another gori is running on %s, wait for it to finish or use --no-lock": "er draait al een gori op %s, wacht tot die klaar is of gebruik --no-lock",
+	"no visit session of %s to resume":                                      "geen bezoek aan %s om te hervatten",
+	"Resuming the visit of %s from %s, %d project(s) left.\n":               "Bezoek aan %s van %s wordt hervat, nog %d project(en).\n",
+	"Left off at %s, gori visit --resume continues from there.\n":           "Gebleven bij %s, gori visit --resume gaat daar verder.\n",
+	"Acknowledged %s, it is left out until its status changes.\n":           "%s erkend, het blijft weg tot de status verandert.\n",
+	"%d acknowledged repository(s) left out until their status changes.\n":  "%d erkende repository('s) weggelaten tot hun status verandert.\n",
+	"Not done yet: %s\n":                       "Nog niet klaar: %s\n",
+	"%s is clean now.\n":                       "%s is nu schoon.\n",
+	"Resolved %d of %d project(s), %d left:\n": "%d van %d project(en) opgelost, nog %d:\n",
+	"  %s -> clean\n":                          "  %s -> schoon\n",
+	"Moved the snoozes of %s to %s.\n":         "De snoozes van %s zijn verplaatst naar %s.\n",
+	"Moved repositories:":                      "Verplaatste repository's:",
+	"Their snoozes still apply, --migrate-moved updates the ignore file.": "Hun snoozes gelden nog, --migrate-moved werkt het ignore-bestand bij.",
+}
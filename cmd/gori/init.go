@@ -0,0 +1,231 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/hansbogert/gori"
+)
+
+var initSettings bool
+var initForce bool
+
+const starterIgnore = `// Snoozes and check overrides of the repositories in this directory, see
+// https://github.com/hansbogert/gori#configuration
+version: %d
+repos: [
+	// {
+	// 	// relative to this file, globs like "forks/*" are allowed
+	// 	path: "some-project"
+	// 	// identifies the repository when it moves, filled in by gori snooze
+	// 	url: "git@github.com:you/some-project.git"
+	// 	// findings are hidden until the given local time
+	// 	snooze: {
+	// 		dirty_workdir:  "2030-01-01 00:00:00"
+	// 		stashes:        "2030-01-01 00:00:00"
+	// 		not_upstreamed: "2030-01-01 00:00:00"
+	// 	}
+	// 	// checks set to false are never reported for this repository
+	// 	checks: upstream: false
+	// },
+]
+`
+
+const starterSettings = `// Personal gori preferences, see https://github.com/hansbogert/gori#configuration
+
+// command used by the (o)pen action, defaults to $VISUAL or $EDITOR
+// editor: "code ."
+
+// command used by the (g)it-ui action, defaults to the first of lazygit, tig
+// or gitui found on the PATH
+// git_ui: "tig"
+
+// pager for output longer than the terminal, like that of the (s)tatus
+// action, defaults to $PAGER or less; "cat" turns paging off
+// pager: "less -R"
+
+// show snoozed findings dimmed with 💤 instead of hiding them
+// show_snoozed: true
+
+// show how long ago the last commit of each repository was made
+// show_age: true
+
+// what repository names link to in terminals which support it, "dir" for
+// their directory or "remote" for the web page of their origin
+// hyperlink_target: "remote"
+
+// working directories whose changes are all younger than this are not
+// reported dirty yet, so work in progress is left alone while it is edited
+// dirty_grace: "4h"
+
+// how long before expiry gori status points out a snooze, "0" disables it
+// snooze_expiry_warning: "3d"
+
+// snoozes expired for this long are pruned by gori snooze prune, on every
+// gori status run as well with auto_prune_snoozes
+// snooze_prune_after: "30d"
+// auto_prune_snoozes: true
+
+// what (i)gnore without arguments and gori snooze add without --for and
+// --check snooze
+// snooze_duration: "1w"
+// snooze_check: "all"
+
+// directories on a network mount (NFS, SMB, FUSE) other than the scan
+// root's are skipped by default, "last" checks them after the others and
+// "scan" treats them like any other
+// network_mounts: "last"
+
+// directories with more worktree files than this are oversized, they are
+// skipped or, with large_repos: "last", checked after the others; "alone"
+// checks them after the others one at a time, keeping memory use down
+// max_repo_files: 200000
+// large_repos: "last"
+
+// directories discovery leaves out entirely, so huge irrelevant trees are
+// never scanned: a name matches directories of that name, a path ending in
+// /** the directory and everything below it, relative to the scan root. Only
+// the directories directly under the scan root are discovered, so a path
+// like thirdparty/zlib never matches
+// exclude_dirs: ["archive", "thirdparty/**"]
+
+// how long the repositories found under a scan root are remembered, "0"
+// disables it; adding or removing a repository, or --rediscover, looks again
+// discovery_cache_ttl: "1h"
+
+// look up the pull or merge request of branches which are not upstreamed:
+// an open one shows them as in review, a merged one, squashed or rebased too,
+// makes them upstreamed; the token of gh or glab is used, see the README
+// pull_requests: true
+
+// show the CI outcome of HEAD, listing pushed repositories with failing CI as
+// well
+// ci_status: true
+
+// flag clones whose origin was deleted or archived, asking the hosting
+// provider or, for other hosts, the remote itself
+// detect_orphans: true
+
+// flag tags pointing at another commit than the tag of the same name on origin
+// check_tags: true
+
+// how long answers of the hosting APIs are reused without asking again, "0"
+// revalidates them every time
+// hosting_cache_ttl: "15m"
+
+// self-hosted instances to look up pull requests on, of type github, gitlab,
+// gitea or forgejo; github.com, gitlab.com and codeberg.org are known
+// already and api defaults to the usual endpoint; token_env names the
+// environment variable with the token of the host
+// hosts: [
+// 	{host: "gitlab.example.com", type: "gitlab"},
+// 	{host: "git.example.com", type: "forgejo", token_env: "WORK_FORGEJO_TOKEN"},
+// ]
+
+// append a JSON line summing up every status run to this file
+// run_log: "~/.local/state/gori/runs.jsonl"
+
+// the remote and the references gori secure pushes its backups to
+// backup_remote:    "origin"
+// backup_namespace: "refs/gori/backup/"
+
+// the hosts gori fleet reports on, scanned over ssh like with --host
+// fleet: [
+// 	{name: "nas", host: "me@nas.local:/srv/repos"},
+// ]
+
+// extra actions offered while visiting projects, {{.Path}} and {{.Name}} are
+// replaced by the project's absolute path and directory name
+// actions: [
+// 	{key: "t", label: "make (t)est", command: "make -C {{.Path}} test"},
+// ]
+
+// check profiles of scan roots, used when scanning the root or a directory
+// below it: which checks apply, before the repositories' own .gori.cue and
+// ignore entries, and the severity of failing each, "warning" by default. A
+// repository failing an "error" check makes gori exit with code 3.
+// roots: [
+// 	{path: "~/work", severity: {dirty: "error", upstream: "error"}},
+// 	{path: "~/playground", checks: {stash: false, upstream: false}},
+// ]
+
+// policies repositories are held to while scanning, their violations listed
+// after the results; require is unified with the facts of each repository
+// matching path, all of them without one: dirty, stashes, upstreamed,
+// days_since_commit and remote. A violated policy of severity "error" makes
+// gori exit with code 3, "warning" ones are only listed. A repository whose
+// last commit is unknown violates the policies about days_since_commit.
+// policies: [
+// 	{
+// 		name:     "work is upstreamed within 3 days"
+// 		path:     "~/src/work/*"
+// 		severity: "error"
+// 		require:  {upstreamed: true} | {days_since_commit: <=3}
+// 	},
+// 	// stashes are allowed everywhere, so no policy mentions them
+// ]
+`
+
+// newInitCmd builds the command writing starter config files
+func newInitCmd() *cobra.Command {
+	initCmd := &cobra.Command{
+		Use:               "init [path]",
+		Short:             "Write a commented starter .goriignore.cue, and optionally gori.cue",
+		RunE:              runInit,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeScanRoot,
+	}
+	initCmd.Flags().BoolVar(&initSettings, "settings", false, "also write the user's gori.cue settings file")
+	initCmd.Flags().BoolVarP(&initForce, "force", "f", false, "overwrite existing files")
+	return initCmd
+}
+
+func runInit(cmd *cobra.Command, args []string) error {
+	ignoreFile := filepath.Join(scanPathArg(args), ".goriignore.cue")
+	if err := writeStarter(ignoreFile, fmt.Sprintf(starterIgnore, gori.IgnoreConfigVersion)); err != nil {
+		return err
+	}
+
+	if initSettings {
+		settingsFile, err := gori.SettingsPath()
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(settingsFile), 0755); err != nil {
+			return err
+		}
+		if err := writeStarter(settingsFile, starterSettings); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeStarter writes content to file, unless it exists and --force is not
+// given
+func writeStarter(file string, content string) error {
+	flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if initForce {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+	f, err := os.OpenFile(file, flags, 0644)
+	if errors.Is(err, os.ErrExist) {
+		return fmt.Errorf("%s already exists, use --force to overwrite it", file)
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(content); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Println("Wrote", file)
+	return nil
+}
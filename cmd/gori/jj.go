@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// jjDir marks a Jujutsu repository, a colocated one has a .git next to it
+const jjDir = ".jj"
+
+// jj revsets of the checks. The working-copy commit @ is always there, it
+// only holds work in progress when it has changes nobody pushed, unpushed
+// work are the other commits with changes no remote bookmark contains.
+const (
+	jjWorkInProgress = "@ ~ ::remote_bookmarks() ~ empty()"
+	jjUnpushed       = "(::@- ~ ::remote_bookmarks()) ~ empty()"
+)
+
+// jjBackend checks Jujutsu repositories with jj's model, as the git
+// repository of a colocated one has a detached HEAD and looks dirty whenever
+// the working-copy commit has changes. Without jj installed, a colocated one
+// is checked through its git repository instead.
+type jjBackend struct{}
+
+func (jjBackend) Name() string { return "jj" }
+
+func (jjBackend) Discover(repoPath string) bool {
+	info, err := os.Stat(filepath.Join(repoPath, jjDir))
+	return err == nil && info.IsDir()
+}
+
+// gitFallback returns the git backend when jj is not installed and the
+// repository is colocated, or nil when jj is installed
+func (jjBackend) gitFallback(repoPath string) (Backend, error) {
+	if _, err := exec.LookPath("jj"); err == nil {
+		return nil, nil
+	}
+	if !isColocated(repoPath) {
+		return nil, errors.New("Jujutsu repository, but jj is not installed")
+	}
+	return gitBackend{}, nil
+}
+
+func (b jjBackend) Status(repoPath string) (bool, string, error) {
+	if fallback, err := b.gitFallback(repoPath); fallback != nil || err != nil {
+		if err != nil {
+			return false, "", err
+		}
+		return fallback.Status(repoPath)
+	}
+	wip, err := jjMatches(repoPath, jjWorkInProgress)
+	if err != nil || !wip {
+		return false, "", err
+	}
+	changes, err := jj(repoPath, "diff", "--summary", "-r", "@")
+	return true, changes, err
+}
+
+func (b jjBackend) UpstreamState(repoPath string) (bool, []string, error) {
+	if fallback, err := b.gitFallback(repoPath); fallback != nil || err != nil {
+		if err != nil {
+			return false, nil, err
+		}
+		upstreamed, problems, err := fallback.UpstreamState(repoPath)
+		return upstreamed, append([]string{"jj is not installed, checked its git repository instead"}, problems...), err
+	}
+	unpushed, err := jjMatches(repoPath, jjUnpushed)
+	return !unpushed, nil, err
+}
+
+// Stashes reports none, jj has no stashes as work in progress lives in
+// commits
+func (b jjBackend) Stashes(repoPath string) (int, error) {
+	if fallback, err := b.gitFallback(repoPath); fallback != nil || err != nil {
+		if err != nil {
+			return 0, err
+		}
+		return fallback.Stashes(repoPath)
+	}
+	return 0, nil
+}
+
+// Details reads the commit time of the working-copy commit's parent and the
+// URL of the origin remote
+func (b jjBackend) Details(repoPath string) (time.Time, string) {
+	if fallback, _ := b.gitFallback(repoPath); fallback != nil {
+		return fallback.Details(repoPath)
+	}
+	return jjLastCommit(repoPath), jjOriginURL(repoPath)
+}
+
+// isColocated reports whether the Jujutsu repository shares its directory with
+// a git repository
+func isColocated(repoPath string) bool {
+	info, err := os.Stat(filepath.Join(repoPath, ".git"))
+	return err == nil && info.IsDir()
+}
+
+// jj runs a jj command in the repository and returns its output
+func jj(repoPath string, args ...string) (string, error) {
+	cmd := exec.Command("jj", append([]string{"--repository", repoPath, "--color", "never", "--no-pager"}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("jj %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return string(out), nil
+}
+
+// jjMatches reports whether the revset selects any commit
+func jjMatches(repoPath, revset string) (bool, error) {
+	out, err := jj(repoPath, "log", "--no-graph", "-r", revset, "-T", `commit_id.short() ++ "\n"`)
+	return strings.TrimSpace(out) != "", err
+}
+
+// jjLastCommit returns the commit time of the working-copy commit's parent,
+// or the zero time if it cannot be determined
+func jjLastCommit(repoPath string) time.Time {
+	out, err := jj(repoPath, "log", "--no-graph", "-r", "@-", "-T", `committer.timestamp().format("%s") ++ "\n"`)
+	if err != nil {
+		return time.Time{}
+	}
+	seconds, err := strconv.ParseInt(strings.TrimSpace(out), 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(seconds, 0)
+}
+
+// jjOriginURL returns the URL of the origin remote, or an empty string when
+// it has none
+func jjOriginURL(repoPath string) string {
+	out, err := jj(repoPath, "git", "remote", "list")
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(out, "\n") {
+		if name, url, ok := strings.Cut(line, " "); ok && name == "origin" {
+			return strings.TrimSpace(url)
+		}
+	}
+	return ""
+}
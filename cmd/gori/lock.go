@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+var noLock bool
+
+// lockAnnotation marks the commands taking the lock of their scan root
+const lockAnnotation = "gori/lock"
+
+// lockFlagAnnotation names the flag holding the scan root of the commands
+// whose arguments are not one
+const lockFlagAnnotation = "gori/lock-flag"
+
+// errLocked is the error of a lock held by another process
+var errLocked = errors.New("locked")
+
+// scanRootLock is the lock file held by this run, nil when none is
+var scanRootLock *os.File
+
+// locksScanRoot makes the command take the lock of its scan root before it
+// runs, so that overlapping runs, like one from cron and an interactive one,
+// neither duplicate work nor race on the ignore and cache files
+func locksScanRoot(cmd *cobra.Command) *cobra.Command {
+	if cmd.Annotations == nil {
+		cmd.Annotations = map[string]string{}
+	}
+	cmd.Annotations[lockAnnotation] = "true"
+	return cmd
+}
+
+// locksScanRootOf is locksScanRoot for the commands naming their scan root
+// with the flag instead of their arguments, like the snooze commands
+// writing the ignore file
+func locksScanRootOf(flag string, cmd *cobra.Command) *cobra.Command {
+	locksScanRoot(cmd)
+	cmd.Annotations[lockFlagAnnotation] = flag
+	return cmd
+}
+
+// lockedScanRoot returns the scan root whose lock the command takes
+func lockedScanRoot(cmd *cobra.Command, args []string) string {
+	if flag, ok := cmd.Annotations[lockFlagAnnotation]; ok {
+		return cmd.Flag(flag).Value.String()
+	}
+	return scanPathArg(args)
+}
+
+// lockScanRoot takes the lock of the scan root, held until gori exits
+func lockScanRoot(scanPath string) error {
+	lockFile, err := scanRootLockFile(scanPath)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(lockFile), 0755); err != nil {
+		return fmt.Errorf("creating lock directory: %w", err)
+	}
+
+	f, err := openLocked(lockFile)
+	if errors.Is(err, errLocked) {
+		holder := scanPath
+		// Windows does not let the file be read while it is locked
+		if content, err := os.ReadFile(lockFile); err == nil && len(bytes.TrimSpace(content)) > 0 {
+			holder += fmt.Sprintf(" (pid %s)", bytes.TrimSpace(content))
+		}
+		return fmt.Errorf(tr("another gori is running on %s, wait for it to finish or use --no-lock"), holder)
+	}
+	if err != nil {
+		return fmt.Errorf("locking %s: %w", scanPath, err)
+	}
+	// the pid is only there to tell who holds the lock
+	if err := f.Truncate(0); err == nil {
+		_, _ = f.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	}
+	scanRootLock = f
+	return nil
+}
+
+// unlockScanRoot releases the lock of this run, if it holds one
+func unlockScanRoot() {
+	if scanRootLock != nil {
+		scanRootLock.Close()
+		scanRootLock = nil
+	}
+}
+
+// scanRootLockFile returns the lock file of the scan root, next to the cached
+// discoveries
+func scanRootLockFile(scanPath string) (string, error) {
+	absRoot, err := filepath.Abs(scanPath)
+	if err != nil {
+		return "", err
+	}
+	return scanRootCacheFile(absRoot, "locks", ".lock")
+}
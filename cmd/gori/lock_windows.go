@@ -0,0 +1,31 @@
+//go:build windows
+
+package main
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// errorSharingViolation is the error of opening a file another process has
+// open without sharing it
+const errorSharingViolation syscall.Errno = 32
+
+// openLocked opens the lock file without sharing it, which the system undoes
+// when gori exits, crashed or not. It returns errLocked when another process
+// has it open.
+func openLocked(lockFile string) (*os.File, error) {
+	name, err := syscall.UTF16PtrFromString(lockFile)
+	if err != nil {
+		return nil, err
+	}
+	h, err := syscall.CreateFile(name, syscall.GENERIC_READ|syscall.GENERIC_WRITE, 0, nil, syscall.OPEN_ALWAYS, syscall.FILE_ATTRIBUTE_NORMAL, 0)
+	if errors.Is(err, errorSharingViolation) {
+		return nil, errLocked
+	}
+	if err != nil {
+		return nil, err
+	}
+	return os.NewFile(uintptr(h), lockFile), nil
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+)
+
+var verbose bool
+var debug bool
+var logFormat string
+var logTarget string
+
+// summaryLogger receives the summary of every run. It is only set when logging
+// to syslog, where a run from a timer leaves no other trace.
+var summaryLogger *slog.Logger
+
+// setupLogging installs the logger selected by --verbose, --debug,
+// --log-format and --log-target. Warnings are always shown, --verbose adds what gori is doing
+// and --debug how it decides.
+func setupLogging(w io.Writer) error {
+	level := slog.LevelWarn
+	if verbose {
+		level = slog.LevelInfo
+	}
+	if debug {
+		level = slog.LevelDebug
+	}
+
+	var handler slog.Handler
+	switch logFormat {
+	case "text":
+		handler = &plainHandler{out: w, level: level, mu: &sync.Mutex{}}
+	case "json":
+		handler = slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level})
+	default:
+		return fmt.Errorf("unknown log format %q, use text or json", logFormat)
+	}
+	switch logTarget {
+	case "stderr":
+	case "syslog":
+		var summaries slog.Handler
+		var err error
+		handler, summaries, err = openSyslog(level)
+		if err != nil {
+			return fmt.Errorf("logging to syslog: %w", err)
+		}
+		summaryLogger = slog.New(summaries)
+	default:
+		return fmt.Errorf("unknown log target %q, use stderr or syslog", logTarget)
+	}
+	slog.SetDefault(slog.New(handler))
+	return nil
+}
+
+// plainHandler writes records the way gori always reported problems, e.g.
+// "Warning: loading settings: <err>", followed by any other attributes as
+// key="value"
+type plainHandler struct {
+	out   io.Writer
+	level slog.Level
+	mu    *sync.Mutex
+	attrs []slog.Attr
+}
+
+var levelNames = map[slog.Level]string{
+	slog.LevelDebug: "Debug",
+	slog.LevelInfo:  "Info",
+	slog.LevelWarn:  "Warning",
+	slog.LevelError: "Error",
+}
+
+func (h *plainHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+func (h *plainHandler) Handle(_ context.Context, r slog.Record) error {
+	line := fmt.Sprintf("%s: %s\n", levelNames[r.Level], formatRecord(h.attrs, r))
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.out, line)
+	return err
+}
+
+// formatRecord returns the message, the error and the other attributes of the
+// record, without its level
+func formatRecord(handlerAttrs []slog.Attr, r slog.Record) string {
+	var errText string
+	var attrs strings.Builder
+	writeAttr := func(a slog.Attr) bool {
+		if a.Key == "err" {
+			if a.Value.Any() != nil {
+				errText = ": " + a.Value.String()
+			}
+		} else {
+			fmt.Fprintf(&attrs, " %s=%q", a.Key, a.Value.String())
+		}
+		return true
+	}
+	for _, a := range handlerAttrs {
+		writeAttr(a)
+	}
+	r.Attrs(writeAttr)
+	return r.Message + errText + attrs.String()
+}
+
+func (h *plainHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.attrs = append(slices.Clone(h.attrs), attrs...)
+	return &clone
+}
+
+// groups are not used by gori, their attributes are written ungrouped
+func (h *plainHandler) WithGroup(name string) slog.Handler {
+	return h
+}
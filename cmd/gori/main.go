@@ -0,0 +1,144 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	buildinfo "runtime/debug"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+
+	"github.com/hansbogert/gori"
+)
+
+var concurrency int
+
+// devVersion is the version of a gori built from source
+const devVersion = "dev"
+
+// version is set for releases with -ldflags "-X main.version=v1.2.3"
+var version = devVersion
+
+func Main() int {
+	main()
+	return 0
+}
+
+func main() {
+	err := newRootCmd().Execute()
+	unlockScanRoot()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, tr("Error: %s\n"), err)
+		var exit exitError
+		if errors.As(err, &exit) {
+			os.Exit(exit.code)
+		}
+		os.Exit(1)
+	}
+}
+
+// newRootCmd builds the command tree, a bare gori invocation is an alias for
+// gori status
+func newRootCmd() *cobra.Command {
+	rootCmd := &cobra.Command{
+		Use:               "gori [path]",
+		RunE:              runStatus,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeScanRoot,
+		// errors are reported by main, usage is only shown on request
+		SilenceErrors: true,
+		SilenceUsage:  true,
+		Version:       buildVersion(),
+	}
+	locksScanRoot(rootCmd)
+	addStatusFlags(rootCmd)
+	rootCmd.PersistentFlags().StringVarP(&concurrencyFlag, "concurrency", "c", "8", "maximum number of concurrent git operations, or auto to size it for the CPUs and storage")
+	rootCmd.PersistentFlags().DurationVar(&repoTimeout, "repo-timeout", 0, "give up on a repository after this long and report it as timed out, e.g. 30s (default no limit)")
+	rootCmd.PersistentFlags().BoolVar(&rediscover, "rediscover", false, "look for repositories again instead of using the cached discovery")
+	rootCmd.PersistentFlags().BoolVar(&includeHidden, "hidden", false, "also scan dot-directories, e.g. ~/.dotfiles")
+	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "log what gori is doing")
+	rootCmd.PersistentFlags().BoolVar(&debug, "debug", false, "log how gori decides, e.g. which upstream branch it compares with")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", "text", "format of the log on stderr, text or json")
+	rootCmd.PersistentFlags().StringVar(&logTarget, "log-target", "stderr", "where the log goes, stderr or syslog, which journald collects too")
+	rootCmd.PersistentFlags().BoolVar(&asciiOutput, "ascii", false, "show the status with ASCII instead of emoji, the default on the classic Windows console")
+	rootCmd.PersistentFlags().BoolVar(&noLock, "no-lock", false, "run even when another gori is running on the same scan root")
+	rootCmd.PersistentFlags().BoolVar(&accessibleOutput, "accessible", false, "make the output easy to follow with a screen reader: words instead of emoji, no columns and the counts first")
+	_ = rootCmd.RegisterFlagCompletionFunc("concurrency", completeValues([]string{"auto"}))
+	_ = rootCmd.RegisterFlagCompletionFunc("log-format", completeValues([]string{"text", "json"}))
+	_ = rootCmd.RegisterFlagCompletionFunc("log-target", completeValues([]string{"stderr", "syslog"}))
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if err := setupLogging(os.Stderr); err != nil {
+			return err
+		}
+		if !cmd.Flags().Changed("ascii") {
+			asciiOutput = legacyConsole()
+		}
+		var err error
+		concurrency, err = resolveConcurrency(concurrencyFlag, scanPathArg(args))
+		if err != nil {
+			return err
+		}
+		if _, ok := cmd.Annotations[lockAnnotation]; ok && !noLock {
+			return lockScanRoot(lockedScanRoot(cmd, args))
+		}
+		return nil
+	}
+
+	rootCmd.AddCommand(
+		locksScanRoot(newStatusCmd()),
+		locksScanRoot(newVisitCmd()),
+		newSnoozeCmd(),
+		newExecCmd(),
+		locksScanRoot(newFetchCmd()),
+		locksScanRoot(newPushCmd()),
+		locksScanRoot(newGCCmd()),
+		locksScanRoot(newPruneBranchesCmd()),
+		locksScanRoot(newWIPCmd()),
+		locksScanRoot(newSecureCmd()),
+		newCloneCmd(),
+		newManifestCmd(),
+		newConfigCmd(),
+		newInitCmd(),
+		newDoctorCmd(),
+		newBenchCmd(),
+		locksScanRoot(newReportCmd()),
+		newSelfUpdateCmd(),
+		newVersionCmd(),
+		newFleetCmd(),
+	)
+	return rootCmd
+}
+
+// buildVersion is the version of this gori, as set for a release or by go
+// install of a tagged module version
+func buildVersion() string {
+	if version != devVersion {
+		return version
+	}
+	if info, ok := buildinfo.ReadBuildInfo(); ok && strings.HasPrefix(info.Main.Version, "v") {
+		return info.Main.Version
+	}
+	return devVersion
+}
+
+// loadSettings loads the user's settings, falling back to the defaults when
+// they cannot be read. They are only loaded, and warned about, once.
+var loadSettings = sync.OnceValue(func() *gori.Settings {
+	settings, err := gori.LoadSettings()
+	if err != nil {
+		slog.Warn("loading settings", "err", err)
+	}
+	return settings
+})
+
+// scanPathArg returns the path to scan, the positional argument if given and
+// the current directory otherwise
+func scanPathArg(args []string) string {
+	if len(args) > 0 {
+		return args[0]
+	}
+	return "./"
+}
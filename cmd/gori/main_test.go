@@ -0,0 +1,540 @@
+package main
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"github.com/hansbogert/gori"
+)
+
+var (
+	dirty, dirtyErr                               = git.PlainOpen("test/local-dirty")
+	upToDate, upToDateErr                         = git.PlainOpen("test/up-to-date")
+	noUpstream, noUpstreamErr                     = git.PlainOpen("test/no-upstream")
+	notUpstreamed, notUpstreamedErr               = git.PlainOpen("test/not-upstreamed")
+	behindUpstream, behindUpstreamErr             = git.PlainOpen("test/behind-upstream")
+	behindUpstreamMaster, behindUpstreamMasterErr = git.PlainOpen("test/behind-upstream-master")
+	featBehindUpstream, featBehindUpstreamErr     = git.PlainOpen("test/feat-behind-upstream")
+)
+
+func Test_isBranchUpstreamed(t *testing.T) {
+	type args struct {
+		repo       *git.Repository
+		branchName string
+	}
+	tests := []struct {
+		name string
+		args args
+		want bool
+		err  error
+	}{
+		{
+			name: "no-upstream",
+			args: args{
+				noUpstream,
+				"main",
+			},
+			want: false,
+			err:  plumbing.ErrReferenceNotFound,
+		},
+		{
+			name: "up-to-date",
+			args: args{
+				upToDate,
+				"main",
+			},
+			want: true,
+			err:  nil,
+		},
+		{
+			name: "not-upstreamed",
+			args: args{
+				notUpstreamed,
+				"main",
+			},
+			want: false,
+			err:  nil,
+		},
+		{
+			name: "behind upstream",
+			args: args{
+				behindUpstream,
+				"main",
+			},
+			want: true,
+			err:  nil,
+		},
+		{
+			name: "behind upstream with feature branch",
+			args: args{
+				featBehindUpstream,
+				"feat",
+			},
+			want: false,
+			err:  plumbing.ErrReferenceNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := isBranchUpstreamed(tt.args.repo, tt.args.branchName, tt.args.branchName)
+			if err != tt.err {
+				t.Errorf("isBranchUpstreamed() error = %v, expected err = %v", err, tt.err)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("isBranchUpstreamed() = %v, expected =  %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_isUpstreamed(t *testing.T) {
+	type args struct {
+		repo     *git.Repository
+		repoPath string
+		ref      *plumbing.Reference
+	}
+	tests := []struct {
+		name string
+		args args
+		want bool
+	}{
+		{
+			name: "feat is behind upstream main",
+			args: args{
+				repo:     featBehindUpstream,
+				repoPath: "doesnt even matter",
+			},
+			want: true,
+		},
+		{
+			name: "feat is upstreamed in main",
+			args: args{
+				repo:     behindUpstream,
+				repoPath: "doesnt even matter",
+			},
+			want: true,
+		},
+		{
+			name: "feat is upstreamed in master",
+			args: args{
+				repo:     behindUpstreamMaster,
+				repoPath: "doesnt even matter",
+			},
+			want: true,
+		},
+		{
+			name: "not upstreamed in main",
+			args: args{
+				repo:     notUpstreamed,
+				repoPath: "doesnt even matter",
+			},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := isUpstreamed(tt.args.repo, tt.args.repoPath); got != tt.want {
+				t.Errorf("isUpstreamed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_editorCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings gori.Settings
+		visual   string
+		editor   string
+		want     []string
+	}{
+		{
+			name:     "configured command with arguments",
+			settings: gori.Settings{Editor: "code --new-window ."},
+			editor:   "vim",
+			want:     []string{"code", "--new-window", "."},
+		},
+		{
+			name:   "visual takes precedence over editor",
+			visual: "gvim",
+			editor: "vim",
+			want:   []string{"gvim", "."},
+		},
+		{
+			name:   "editor as fallback",
+			editor: "vim",
+			want:   []string{"vim", "."},
+		},
+		{
+			name: "nothing configured",
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("VISUAL", tt.visual)
+			t.Setenv("EDITOR", tt.editor)
+			got, err := editorCommand(&tt.settings)
+			if tt.want == nil && err == nil {
+				t.Errorf("editorCommand() = %v, expected an error", got)
+				return
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("editorCommand() = %v, expected = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_pagerCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings gori.Settings
+		pager    string
+		want     []string
+	}{
+		{
+			name:     "configured command with arguments",
+			settings: gori.Settings{Pager: "less -R"},
+			pager:    "more",
+			want:     []string{"less", "-R"},
+		},
+		{
+			name:  "pager as fallback",
+			pager: "most",
+			want:  []string{"most"},
+		},
+		{
+			name: "nothing configured",
+			want: []string{"less"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("PAGER", tt.pager)
+			if got := pagerCommand(&tt.settings); !slices.Equal(got, tt.want) {
+				t.Errorf("pagerCommand() = %v, expected = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_messageLanguage(t *testing.T) {
+	tests := []struct {
+		name       string
+		lcAll      string
+		lcMessages string
+		lang       string
+		want       string
+	}{
+		{name: "lang", lang: "nl_NL.UTF-8", want: "nl"},
+		{name: "lang with modifier", lang: "nl_BE@euro", want: "nl"},
+		{name: "lc_messages over lang", lcMessages: "de_DE.UTF-8", lang: "nl_NL.UTF-8", want: "de"},
+		{name: "lc_all over everything", lcAll: "C", lcMessages: "de_DE.UTF-8", lang: "nl_NL.UTF-8", want: "c"},
+		{name: "nothing set", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("LC_ALL", tt.lcAll)
+			t.Setenv("LC_MESSAGES", tt.lcMessages)
+			t.Setenv("LANG", tt.lang)
+			if got := messageLanguage(); got != tt.want {
+				t.Errorf("messageLanguage() = %v, expected = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// Test_translations checks that translated format strings keep the verbs of
+// the English message, in the same order
+func Test_translations(t *testing.T) {
+	verbs := regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z%]`)
+	for lang, messages := range translations {
+		for message, translated := range messages {
+			if got, want := verbs.FindAllString(translated, -1), verbs.FindAllString(message, -1); !slices.Equal(got, want) {
+				t.Errorf("%s translation of %q has verbs %v, expected = %v", lang, message, got, want)
+			}
+		}
+	}
+}
+
+func Test_parseSelection(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []int
+		wantErr bool
+	}{
+		{name: "empty selects all", input: "\n", want: []int{0, 1, 2, 3, 4}},
+		{name: "single", input: "2", want: []int{1}},
+		{name: "list and range", input: "5,1-3", want: []int{0, 1, 2, 4}},
+		{name: "duplicates", input: "2 2,1-2", want: []int{0, 1}},
+		{name: "out of range", input: "6", wantErr: true},
+		{name: "reversed range", input: "3-1", wantErr: true},
+		{name: "not a number", input: "foo", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSelection(tt.input, 5)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseSelection() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("parseSelection() = %v, expected = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_humanSize(t *testing.T) {
+	tests := []struct {
+		size int64
+		want string
+	}{
+		{size: 0, want: "0 B"},
+		{size: 1023, want: "1023 B"},
+		{size: 1024, want: "1.0 KiB"},
+		{size: 1536, want: "1.5 KiB"},
+		{size: 5 * 1024 * 1024 * 1024, want: "5.0 GiB"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := humanSize(tt.size); got != tt.want {
+				t.Errorf("humanSize() = %v, expected = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_mountFor(t *testing.T) {
+	mountinfo := `22 1 259:2 / / rw,relatime shared:1 - ext4 /dev/nvme0n1p2 rw
+35 22 0:52 / /home/me/nfs rw,relatime shared:2 - nfs4 server:/export rw
+36 22 8:1 / /mnt/my\040disk rw,relatime shared:3 - ext4 /dev/sda1 rw
+`
+	tests := []struct {
+		path string
+		want mount
+	}{
+		{path: "/home/me/src", want: mount{point: "/", device: "259:2", fsType: "ext4"}},
+		{path: "/home/me/nfs/src", want: mount{point: "/home/me/nfs", device: "0:52", fsType: "nfs4"}},
+		{path: "/home/me/nfsish", want: mount{point: "/", device: "259:2", fsType: "ext4"}},
+		{path: "/mnt/my disk/src", want: mount{point: "/mnt/my disk", device: "8:1", fsType: "ext4"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, ok := mountFor(strings.NewReader(mountinfo), tt.path)
+			if !ok || got != tt.want {
+				t.Errorf("mountFor() = %v, %v, expected = %v", got, ok, tt.want)
+			}
+		})
+	}
+}
+
+func Test_installRelease(t *testing.T) {
+	binary := []byte("new gori")
+	sum := sha256.Sum256(binary)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/gori_linux_amd64", func(w http.ResponseWriter, r *http.Request) { w.Write(binary) })
+	mux.HandleFunc("/checksums.txt", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%x  gori_linux_amd64\n%x  gori_darwin_arm64\n", sum, sha256.Sum256([]byte("other")))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	latest := &release{Tag: "v1.0.0", Assets: []releaseAsset{
+		{Name: "gori_linux_amd64", URL: server.URL + "/gori_linux_amd64"},
+		{Name: "gori_darwin_arm64", URL: server.URL + "/gori_linux_amd64"},
+		{Name: "checksums.txt", URL: server.URL + "/checksums.txt"},
+	}}
+	tests := []struct {
+		name    string
+		asset   string
+		wantErr string
+	}{
+		{name: "verified", asset: "gori_linux_amd64"},
+		{name: "checksum mismatch", asset: "gori_darwin_arm64", wantErr: "checksum mismatch"},
+		{name: "missing platform", asset: "gori_plan9_386", wantErr: "no binary gori_plan9_386"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "gori")
+			if err := os.WriteFile(path, []byte("old gori"), 0755); err != nil {
+				t.Fatal(err)
+			}
+			err := installRelease(server.Client(), latest, tt.asset, path)
+			got, _ := os.ReadFile(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("installRelease() error = %v, expected %q", err, tt.wantErr)
+				}
+				if string(got) != "old gori" {
+					t.Errorf("binary = %q, expected it untouched", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("installRelease() error = %v", err)
+			}
+			if string(got) != "new gori" {
+				t.Errorf("binary = %q, expected = %q", got, "new gori")
+			}
+		})
+	}
+}
+
+func Test_compareVersions(t *testing.T) {
+	tests := []struct {
+		a, b       string
+		expect     int
+		comparable bool
+	}{
+		{"v1.2.3", "v1.2.3", 0, true},
+		{"v1.2.3", "v1.10.0", -1, true},
+		{"v2.0.0", "v1.9.9", 1, true},
+		{"v1.3.0-rc.1", "v1.3.0", -1, true},
+		{"v1.3.0-rc.2", "v1.3.0-rc.10", -1, true},
+		{"v1.3.0-alpha", "v1.3.0-1", 1, true},
+		{"v1.3.0+build.1", "v1.3.0", 0, true},
+		{"dev", "v1.0.0", 0, false},
+		{"v1.0", "v1.0.0", 0, false},
+	}
+	for _, tt := range tests {
+		got, comparable := compareVersions(tt.a, tt.b)
+		if got != tt.expect || comparable != tt.comparable {
+			t.Errorf("compareVersions(%s, %s) = %v, %v, expected = %v, %v", tt.a, tt.b, got, comparable, tt.expect, tt.comparable)
+		}
+	}
+}
+
+func Test_unamePlatform(t *testing.T) {
+	tests := map[string]string{
+		"Linux x86_64\n":  "linux/amd64",
+		"Darwin arm64":    "darwin/arm64",
+		"Linux aarch64":   "linux/arm64",
+		"FreeBSD riscv64": "freebsd/riscv64",
+	}
+	for uname, want := range tests {
+		if got := unamePlatform(uname); got != want {
+			t.Errorf("unamePlatform(%q) = %v, expected = %v", uname, got, want)
+		}
+	}
+}
+
+func Test_backendFor(t *testing.T) {
+	root := t.TempDir()
+	tests := map[string]string{
+		"plain":    "git",
+		".git":     "git",
+		".hg":      "hg",
+		".jj":      "jj",
+		".git/.jj": "jj",
+		".git/.hg": "hg",
+	}
+	for markers, want := range tests {
+		repoPath := filepath.Join(root, strings.NewReplacer("/", "+", ".", "_").Replace(markers))
+		for _, marker := range strings.Split(markers, "/") {
+			if err := os.MkdirAll(filepath.Join(repoPath, marker), 0o755); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if got := backendFor(repoPath).Name(); got != want {
+			t.Errorf("backendFor(%s) = %v, expected = %v", markers, got, want)
+		}
+	}
+}
+
+func Test_lockScanRoot(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	scanPath := t.TempDir()
+
+	if err := lockScanRoot(scanPath); err != nil {
+		t.Fatal(err)
+	}
+	held := scanRootLock
+	// a lock is per open file, so a second one conflicts in this process too
+	err := lockScanRoot(scanPath)
+	if err == nil || !strings.Contains(err.Error(), fmt.Sprintf("another gori is running on %s (pid %d)", scanPath, os.Getpid())) {
+		t.Errorf("second lock: got %v, want another gori is running", err)
+	}
+	if err := lockScanRoot(t.TempDir()); err != nil {
+		t.Errorf("other scan root: %v", err)
+	}
+	unlockScanRoot()
+
+	held.Close()
+	if err := lockScanRoot(scanPath); err != nil {
+		t.Errorf("after unlocking: %v", err)
+	}
+	unlockScanRoot()
+}
+
+func Test_lockedScanRoot(t *testing.T) {
+	rootCmd := newRootCmd()
+	tests := []struct {
+		args   []string
+		locks  bool
+		expect string
+	}{
+		{[]string{"status", "ws"}, true, "ws"},
+		// before -p sets the flag's variable for the commands below
+		{[]string{"snooze", "rm", "tool"}, true, "./"},
+		{[]string{"snooze", "add", "tool", "-p", "ws"}, true, "ws"},
+		{[]string{"snooze", "prune", "--path", "ws"}, true, "ws"},
+		{[]string{"snooze", "list", "-p", "ws"}, false, ""},
+	}
+	for _, tt := range tests {
+		cmd, args, err := rootCmd.Find(tt.args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := cmd.ParseFlags(args); err != nil {
+			t.Fatal(err)
+		}
+		if _, locks := cmd.Annotations[lockAnnotation]; locks != tt.locks {
+			t.Errorf("%v locks = %v, expected = %v", tt.args, locks, tt.locks)
+			continue
+		}
+		if got := lockedScanRoot(cmd, cmd.Flags().Args()); tt.locks && got != tt.expect {
+			t.Errorf("lockedScanRoot(%v) = %v, expected = %v", tt.args, got, tt.expect)
+		}
+	}
+}
+
+func Test_writeSession(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	scanPath := t.TempDir()
+	projects := []gori.ProjectStatus{
+		gori.NewProject(filepath.Join(scanPath, "alpha"), true, false, true),
+		gori.NewProject(filepath.Join(scanPath, "beta"), false, false, false),
+	}
+
+	if err := writeSession(scanPath, projects, 1); err != nil {
+		t.Fatal(err)
+	}
+	session, err := loadSession(scanPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"alpha", "beta"}; !slices.Equal(session.Queue, want) || session.Position != 1 {
+		t.Errorf("got queue %v at %d, want %v at 1", session.Queue, session.Position, want)
+	}
+
+	clearSession(scanPath)
+	if _, err := loadSession(scanPath); err == nil {
+		t.Error("session still there after clearing it")
+	}
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/spf13/cobra"
+
+	"github.com/hansbogert/gori"
+)
+
+var manifestFile string
+var manifestFormat string
+var outputFile string
+
+func newCloneCmd() *cobra.Command {
+	cloneCmd := &cobra.Command{
+		Use:               "clone --manifest <file> [path]",
+		Short:             "Clone the repositories of a manifest which are missing",
+		RunE:              runClone,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeScanRoot,
+	}
+	cloneCmd.Flags().StringVarP(&manifestFile, "manifest", "m", "", "manifest listing the repositories, CUE or JSON")
+	cloneCmd.MarkFlagRequired("manifest")
+	return cloneCmd
+}
+
+func newManifestCmd() *cobra.Command {
+	manifestCmd := &cobra.Command{
+		Use:               "manifest [path]",
+		Short:             "Write a manifest of every repository, for use with clone",
+		RunE:              runManifest,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeScanRoot,
+	}
+	manifestCmd.Flags().StringVarP(&manifestFormat, "format", "f", "cue", fmt.Sprintf("format of the manifest, one of %v", gori.ManifestFormats))
+	manifestCmd.Flags().StringVarP(&outputFile, "output", "o", "", "write the manifest to a file instead of stdout")
+	_ = manifestCmd.RegisterFlagCompletionFunc("format", completeValues(gori.ManifestFormats))
+	return manifestCmd
+}
+
+// runClone clones the repositories listed in the manifest which do not exist
+// under the scan path yet. The system git is used so the user's credential
+// helpers and ssh configuration apply.
+func runClone(cmd *cobra.Command, args []string) error {
+	scanPath := scanPathArg(args)
+
+	manifest, err := gori.LoadManifest(manifestFile)
+	if err != nil {
+		return err
+	}
+
+	repos := make(map[string]gori.ManifestRepo)
+	for _, repo := range manifest.Repos {
+		repoPath := filepath.Join(scanPath, repo.Path)
+		if _, err := os.Stat(repoPath); err == nil {
+			continue
+		}
+		if _, url := repo.CloneURL(); url == "" {
+			slog.Warn("no remote to clone from, skipping", "repo", repo.Path)
+			continue
+		}
+		repos[repoPath] = repo
+	}
+
+	var mu sync.Mutex
+	completed := 0
+	errs := forEachRepo(slices.Sorted(maps.Keys(repos)), func(repoPath string) error {
+		err := cloneManifestRepo(repoPath, repos[repoPath])
+
+		mu.Lock()
+		defer mu.Unlock()
+		completed++
+		result := "cloned"
+		if err != nil {
+			result = "failed"
+		}
+		fmt.Printf("[%d/%d] %s: %s\n", completed, len(repos), repos[repoPath].Path, result)
+		return err
+	})
+
+	if len(errs) > 0 {
+		fmt.Println("\nFailed to clone:")
+		for _, repoPath := range slices.Sorted(maps.Keys(errs)) {
+			fmt.Printf("  %s: %s\n", repos[repoPath].Path, errs[repoPath])
+		}
+		return fmt.Errorf("cloning failed for %d of %d repositories", len(errs), len(repos))
+	}
+	return nil
+}
+
+// runManifest describes every repository under the scan path in a manifest
+func runManifest(cmd *cobra.Command, args []string) error {
+	scanPath := scanPathArg(args)
+
+	repoPaths, err := discoverGitRepos(scanPath)
+	if err != nil {
+		return err
+	}
+
+	manifest := &gori.Manifest{Repos: []gori.ManifestRepo{}}
+	for _, repoPath := range repoPaths {
+		repo, err := gori.OpenRepo(repoPath)
+		if err != nil {
+			continue
+		}
+		entry, err := manifestRepo(repo, gori.NewPathResolver(scanPath).Stored(repoPath))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", filepath.Base(repoPath), err)
+			continue
+		}
+		manifest.Repos = append(manifest.Repos, entry)
+	}
+
+	b, err := manifest.Encode(manifestFormat)
+	if err != nil {
+		return err
+	}
+	if outputFile == "" {
+		_, err = os.Stdout.Write(b)
+		return err
+	}
+	return os.WriteFile(outputFile, b, 0644)
+}
+
+// manifestRepo describes a repository for the manifest
+func manifestRepo(repo *git.Repository, path string) (gori.ManifestRepo, error) {
+	entry := gori.ManifestRepo{Path: filepath.ToSlash(path), Remotes: make(map[string]string)}
+
+	remotes, err := repo.Remotes()
+	if err != nil {
+		return entry, fmt.Errorf("listing remotes: %w", err)
+	}
+	for _, remote := range remotes {
+		if urls := remote.Config().URLs; len(urls) > 0 {
+			entry.Remotes[remote.Config().Name] = urls[0]
+		}
+	}
+
+	if originHead, err := repo.Reference(plumbing.NewRemoteHEADReferenceName("origin"), false); err == nil {
+		entry.DefaultBranch = strings.TrimPrefix(originHead.Target().Short(), "origin/")
+	} else if mainish, err := getLikelyUpstreamMainishBranch(repo); err == nil {
+		entry.DefaultBranch = mainish
+	}
+
+	if head, err := repo.Head(); err == nil && head.Name().IsBranch() {
+		entry.Branch = head.Name().Short()
+	}
+	return entry, nil
+}
+
+// cloneManifestRepo clones a repository, adds its other remotes and checks out
+// its branch
+func cloneManifestRepo(repoPath string, repo gori.ManifestRepo) error {
+	origin, url := repo.CloneURL()
+	type step struct {
+		what string
+		args []string
+	}
+	steps := []step{
+		{"cloning", []string{"clone", "--quiet", "--origin", origin, url, repoPath}},
+	}
+	for _, name := range slices.Sorted(maps.Keys(repo.Remotes)) {
+		if name != origin {
+			steps = append(steps, step{"adding remote " + name, []string{"-C", repoPath, "remote", "add", "-f", name, repo.Remotes[name]}})
+		}
+	}
+
+	for _, step := range steps {
+		if output, err := exec.Command("git", step.args...).CombinedOutput(); err != nil {
+			return fmt.Errorf("%s: %w: %s", step.what, err, strings.TrimSpace(string(output)))
+		}
+	}
+
+	// a branch which was never pushed cannot be restored, that should not
+	// fail the whole clone
+	if repo.Branch != "" && repo.Branch != repo.DefaultBranch {
+		if err := exec.Command("git", "-C", repoPath, "checkout", "--quiet", repo.Branch).Run(); err != nil {
+			slog.Warn("branch not found on the remotes, staying on the default branch", "repo", repo.Path, "branch", repo.Branch)
+		}
+	}
+	return nil
+}
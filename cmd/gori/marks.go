@@ -0,0 +1,43 @@
+package main
+
+import "github.com/hansbogert/gori"
+
+var asciiOutput bool
+var accessibleOutput bool
+
+// mark is the symbol of a failed check in the status lines
+type mark struct {
+	emoji string
+	// ascii stands in for emoji on consoles which cannot show it
+	ascii string
+	// label is read out by screen readers, for --accessible
+	label string
+}
+
+func (m mark) String() string {
+	if accessibleOutput {
+		return tr(m.label)
+	}
+	if asciiOutput {
+		return m.ascii
+	}
+	return m.emoji
+}
+
+var (
+	dirtyMark         = mark{"🚧", "[D]", "dirty"}
+	stashMark         = mark{"🗄️", "[S]", "stashed changes"}
+	notUpstreamedMark = mark{"📤", "[U]", "not upstreamed"}
+	divergedMark      = mark{"🔀", "[V]", "diverged"}
+	inReviewMark      = mark{"🔍", "[R]", "in review"}
+	orphanedMark      = mark{"🪦", "[O]", "orphaned"}
+	divergedTagsMark  = mark{"🏷️", "[T]", "tags differ from origin"}
+	snoozedMark       = mark{"💤", "[z]", "snoozed"}
+)
+
+// ciMarks are how the outcomes of CI are shown
+var ciMarks = map[string]mark{
+	gori.CIPassed:  {"✓", "ok", "passed"},
+	gori.CIFailed:  {"✗", "failed", "failed"},
+	gori.CIPending: {"…", "...", "running"},
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// metric is a gauge of the openmetrics format, with its value per repository
+type metric struct {
+	name  string
+	help  string
+	value func(record repoRecord) int
+}
+
+// repoMetrics are the gauges written for every repository which could be
+// checked
+var repoMetrics = []metric{
+	{"gori_repository_dirty", "Whether the repository has uncommitted changes.", func(r repoRecord) int { return count(r.Dirty) }},
+	{"gori_repository_stashes", "How many stashes the repository has.", func(r repoRecord) int { return max(r.Stashes, count(r.Stash)) }},
+	{"gori_repository_upstreamed", "Whether all work of the repository is upstreamed.", func(r repoRecord) int { return count(r.Upstreamed) }},
+	{"gori_repository_snoozed", "Whether a finding of the repository is snoozed.", func(r repoRecord) int { return count(r.Snoozed) }},
+}
+
+// writeMetrics writes the records as OpenMetrics gauges, which the textfile
+// collector of node_exporter picks up when written there with --output.
+// Repositories which could not be checked only have gori_repository_error.
+func writeMetrics(w io.Writer, records []repoRecord) {
+	for _, m := range repoMetrics {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", m.name, m.help, m.name)
+		for _, record := range records {
+			if record.Error == "" {
+				fmt.Fprintf(w, "%s{%s} %d\n", m.name, metricLabels(record), m.value(record))
+			}
+		}
+	}
+
+	fmt.Fprintln(w, "# HELP gori_repository_error Whether the repository could not be checked.\n# TYPE gori_repository_error gauge")
+	for _, record := range records {
+		fmt.Fprintf(w, "gori_repository_error{%s} %d\n", metricLabels(record), count(record.Error != ""))
+	}
+
+	fmt.Fprintln(w, "# HELP gori_last_run_timestamp_seconds When gori last checked the repositories.\n# TYPE gori_last_run_timestamp_seconds gauge")
+	fmt.Fprintf(w, "gori_last_run_timestamp_seconds %d\n", time.Now().Unix())
+	fmt.Fprintln(w, "# EOF")
+}
+
+// metricLabels returns the labels naming the repository of the record
+func metricLabels(record repoRecord) string {
+	labels := fmt.Sprintf(`path="%s"`, escapeLabel(record.Path))
+	if record.Host != "" {
+		labels = fmt.Sprintf(`host="%s",%s`, escapeLabel(record.Host), labels)
+	}
+	return labels
+}
+
+// escapeLabel escapes a label value the way the format requires
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
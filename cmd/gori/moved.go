@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hansbogert/gori"
+)
+
+var migrateMoved bool
+
+// thisRunRemotes are the origin URLs of the repositories checked by this run,
+// by path
+var thisRunRemotes = map[string]string{}
+
+// reportMovedRepos points out the entries of the scan root's ignore file
+// whose repository moved, going by the repositories just checked, and with
+// --migrate-moved points them at the new path
+func reportMovedRepos(scanPath string) error {
+	moved, err := gori.MovedRepos(scanPath, thisRunRemotes)
+	if err != nil || len(moved) == 0 {
+		// a broken ignore file was already reported while scanning
+		return nil
+	}
+	if migrateMoved {
+		if err := gori.MoveRepoEntries(scanPath, moved); err != nil {
+			return err
+		}
+		for _, m := range moved {
+			fmt.Printf(tr("Moved the snoozes of %s to %s.\n"), m.From, m.To)
+		}
+		return nil
+	}
+
+	fmt.Println("\n" + tr("Moved repositories:"))
+	for _, m := range moved {
+		fmt.Printf("  %s -> %s\n", m.From, m.To)
+	}
+	fmt.Println(tr("Their snoozes still apply, --migrate-moved updates the ignore file."))
+	return nil
+}
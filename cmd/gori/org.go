@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// writeOrg writes the flagged records as an org-mode document, a TODO heading
+// per repository failing a check, snoozed or not checked. A snoozed one is
+// scheduled for when its snooze expires, so it shows up in the agenda then.
+func writeOrg(w io.Writer, records []repoRecord) {
+	fmt.Fprintf(w, "#+TITLE: gori %s\n", displayRoot)
+	for _, record := range records {
+		var findings, tags []string
+		if record.Error != "" {
+			findings, tags = append(findings, "could not be checked: "+record.Error), append(tags, "error")
+		}
+		if record.Dirty {
+			findings, tags = append(findings, "dirty working directory"), append(tags, "dirty")
+		}
+		if record.Stash {
+			findings, tags = append(findings, "stashed changes"), append(tags, "stash")
+		}
+		if record.Diverged != nil {
+			findings, tags = append(findings, "diverged from "+record.Diverged.Upstream+", rebase or merge needed"), append(tags, "diverged")
+		} else if !record.Upstreamed && record.Error == "" {
+			findings, tags = append(findings, "not upstreamed"), append(tags, "upstream")
+		}
+		for _, tag := range record.DivergedTags {
+			findings = append(findings, "tag "+tag.Name+" points at another commit than on origin")
+		}
+		if len(record.DivergedTags) > 0 {
+			tags = append(tags, "tags")
+		}
+		if len(findings) == 0 && !record.Snoozed {
+			continue
+		}
+
+		name := filepath.Base(record.Path)
+		if record.Host != "" {
+			name = record.Host + ":" + name
+		}
+		heading := "* TODO " + name
+		if len(tags) > 0 {
+			heading += " :" + strings.Join(tags, ":") + ":"
+		}
+		fmt.Fprintln(w, heading)
+		if !record.SnoozedUntil.IsZero() {
+			fmt.Fprintf(w, "  SCHEDULED: <%s>\n", record.SnoozedUntil.Format("2006-01-02 Mon"))
+		}
+		fmt.Fprintf(w, "  :PROPERTIES:\n  :PATH: %s\n", record.Path)
+		if record.Remote != "" {
+			fmt.Fprintf(w, "  :REMOTE: %s\n", record.Remote)
+		}
+		fmt.Fprintln(w, "  :END:")
+		for _, finding := range findings {
+			fmt.Fprintf(w, "  - %s\n", strings.ReplaceAll(finding, "\n", " "))
+		}
+	}
+}
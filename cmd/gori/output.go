@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	git "github.com/go-git/go-git/v5"
+
+	"github.com/hansbogert/gori"
+)
+
+// OutputFormats are the formats gori status can report in, json and porcelain
+// being meant for scripts, openmetrics for monitoring and org for Emacs
+// org-mode
+var OutputFormats = []string{"text", "json", "porcelain", "openmetrics", "org"}
+
+var outputFormat string
+
+// repoRecord is the machine readable status of a single repository. Error is
+// set when the repository could not be checked, its checks are meaningless
+// then.
+type repoRecord struct {
+	// Host is the machine the repository is on, empty for this one
+	Host       string `json:"host,omitempty"`
+	Path       string `json:"path"`
+	Dirty      bool   `json:"dirty"`
+	Stash      bool   `json:"stash"`
+	Upstreamed bool   `json:"upstreamed"`
+	Snoozed    bool   `json:"snoozed,omitempty"`
+	// SnoozedUntil is when the first of the snoozes expires
+	SnoozedUntil time.Time `json:"snoozed_until,omitzero"`
+	// Stashes is how many stashes there are
+	Stashes int `json:"stashes,omitempty"`
+	// CI is the outcome of the CI of HEAD: passed, failed or pending
+	CI string `json:"ci,omitempty"`
+	// Orphaned is why the origin is gone, deleted or archived
+	Orphaned string `json:"orphaned,omitempty"`
+	// Diverged is how the branch and its upstream went apart, when it is not
+	// upstreamed
+	Diverged *gori.Divergence `json:"diverged,omitempty"`
+	// DivergedTags are the tags pointing at another commit than on origin
+	DivergedTags []gori.DivergedTag `json:"diverged_tags,omitempty"`
+	// PullRequest is the pull request the branch is in review in
+	PullRequest *gori.PullRequest `json:"pull_request,omitempty"`
+	// Remote is the URL of the origin remote
+	Remote string `json:"remote,omitempty"`
+	// LastCommit is the commit time of HEAD
+	LastCommit time.Time `json:"last_commit,omitzero"`
+	Error      string    `json:"error,omitempty"`
+	Problems   []string  `json:"problems,omitempty"`
+	// Violations are the names of the policies the repository violates
+	Violations []string `json:"violations,omitempty"`
+}
+
+// repoRecords checks every repository under scanPath, clean ones included.
+// Directories which are not a git repository are left out.
+func repoRecords(scanPath string) ([]repoRecord, error) {
+	ignoreConfig, err := gori.LoadMergedIgnoreConfig(scanPath)
+	if err != nil {
+		slog.Warn("loading ignore config", "err", err)
+	}
+
+	repoPaths, err := discoverRepos(scanPath)
+	if err != nil {
+		return nil, err
+	}
+
+	records := []repoRecord{}
+	scanProjects(repoPaths, scanPath, ignoreConfig, func(result repoResult) {
+		if errors.Is(result.err, git.ErrRepositoryNotExists) {
+			return
+		}
+		record := repoRecord{Path: result.path, Problems: result.problems}
+		if result.err != nil {
+			record.Error = result.err.Error()
+		} else {
+			record.Dirty = result.status.IsDirty
+			record.Stash = result.status.HasStash
+			if record.Stash {
+				record.Stashes = result.status.Stashes
+			}
+			record.Upstreamed = result.status.Upstreamed
+			record.Snoozed = result.status.Snoozed()
+			record.SnoozedUntil = result.status.SnoozedUntil
+			record.CI = result.status.CI
+			record.Orphaned = result.status.Orphaned
+			record.Diverged = result.status.Diverged
+			record.DivergedTags = result.status.DivergedTags
+			record.PullRequest = result.status.PullRequest
+			record.Remote = result.status.RemoteURL
+			record.LastCommit = result.status.LastCommit
+			for _, policy := range result.violations {
+				record.Violations = append(record.Violations, policy.Name)
+			}
+		}
+		records = append(records, record)
+	})
+	return records, nil
+}
+
+// writeRecords writes the records as JSON, or as porcelain: one line per
+// repository with a D, S and U column for dirty, stashed and not upstreamed,
+// a dot for a passed check. Repositories which could not be checked show !!!
+// followed by the error after a tab.
+func writeRecords(w io.Writer, records []repoRecord, format string) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(records)
+	case "text":
+		writeTextRecords(w, records)
+		return nil
+	case "porcelain":
+		for _, record := range records {
+			path := record.Path
+			if record.Host != "" {
+				path = record.Host + ":" + path
+			}
+			if record.Error != "" {
+				fmt.Fprintf(w, "!!! %s\t%s\n", path, strings.ReplaceAll(record.Error, "\n", " "))
+				continue
+			}
+			fmt.Fprintf(w, "%s%s%s %s\n",
+				flag(record.Dirty, "D"), flag(record.Stash, "S"), flag(!record.Upstreamed, "U"), path)
+		}
+		return nil
+	case "openmetrics":
+		writeMetrics(w, records)
+		return nil
+	case "org":
+		writeOrg(w, records)
+		return nil
+	default:
+		return fmt.Errorf("unknown format %q, use one of %v", format, OutputFormats)
+	}
+}
+
+// writeReport writes the records in the format, or through --report-template,
+// to stdout or, with --output, to the file, and with --copy to the clipboard
+// as well. The file is replaced at once, so a tool reading it never sees half
+// a report.
+func writeReport(records []repoRecord, format string) error {
+	var buf bytes.Buffer
+	if reportTemplate != "" {
+		if err := renderTemplate(&buf, reportTemplate, records); err != nil {
+			return err
+		}
+	} else if err := writeRecords(&buf, records, format); err != nil {
+		return err
+	}
+	if copyReport {
+		if err := copyToClipboard(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	if outputFile == "" {
+		_, err := os.Stdout.Write(buf.Bytes())
+		return err
+	}
+	return writeFileAtomic(outputFile, buf.Bytes())
+}
+
+// writeFileAtomic writes content next to the file and renames it into place
+func writeFileAtomic(file string, content []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(file), "."+filepath.Base(file)+".*")
+	if err != nil {
+		return fmt.Errorf("writing %s: %w", file, err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing %s: %w", file, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing %s: %w", file, err)
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", file, err)
+	}
+	if err := os.Rename(tmp.Name(), file); err != nil {
+		return fmt.Errorf("writing %s: %w", file, err)
+	}
+	return nil
+}
+
+// flag returns letter when set and a dot otherwise
+func flag(set bool, letter string) string {
+	if set {
+		return letter
+	}
+	return "."
+}
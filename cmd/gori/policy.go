@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+
+	"github.com/hansbogert/gori"
+)
+
+// policyExitCode is gori's exit code when a policy with the error severity is
+// violated, or a check of that severity in the scan root's profile fails,
+// apart from the 1 of failing altogether
+const policyExitCode = 3
+
+// loadPolicies loads the policies of the user's settings, none when they
+// cannot be read. They are only loaded, and warned about, once.
+var loadPolicies = sync.OnceValue(func() []gori.Policy {
+	policies, err := gori.LoadPolicies()
+	if err != nil {
+		slog.Warn("loading policies", "err", err)
+	}
+	return policies
+})
+
+// policyViolation is a policy violated by a repository
+type policyViolation struct {
+	path   string
+	policy gori.Policy
+}
+
+// thisRunViolations are the policies violated in this run, in the order of the
+// repositories
+var thisRunViolations []policyViolation
+
+// violatedPolicies returns the policies applying to the project which its
+// facts do not fit
+func violatedPolicies(project gori.ProjectStatus, scanPath string) []gori.Policy {
+	var violated []gori.Policy
+	facts := gori.NewPolicyFacts(project)
+	for _, policy := range loadPolicies() {
+		if policy.AppliesTo(project.Path, scanPath) && policy.ViolatedBy(facts) {
+			violated = append(violated, policy)
+		}
+	}
+	return violated
+}
+
+// exitError is an error making gori exit with its code rather than 1
+type exitError struct {
+	code int
+	msg  string
+}
+
+func (e exitError) Error() string {
+	return e.msg
+}
+
+// reportViolations lists this run's policy violations in a section of their
+// own and returns an exitError when one of an error policy is among them
+func reportViolations(w io.Writer) error {
+	if len(thisRunViolations) == 0 {
+		return nil
+	}
+	errs := 0
+	fmt.Fprintln(w, "\n"+tr("Policy violations:"))
+	for _, v := range thisRunViolations {
+		fmt.Fprintf(w, "  %s: %s (%s)\n", displayName(v.path), v.policy.Name, tr(v.policy.Severity))
+		errs += count(v.policy.Severity == "error")
+	}
+	if errs > 0 {
+		return exitError{code: policyExitCode, msg: fmt.Sprintf(tr("%d policy violation(s) of severity error"), errs)}
+	}
+	return nil
+}
+
+// thisRunFailedErrors counts the repositories of this run failing a check of
+// severity error in the profile of their scan root
+var thisRunFailedErrors int
+
+// failedErrorsError returns an exitError when a repository failed a check of
+// severity error
+func failedErrorsError() error {
+	if thisRunFailedErrors == 0 {
+		return nil
+	}
+	return exitError{code: policyExitCode, msg: fmt.Sprintf(tr("%d repository(s) failing a check of severity error"), thisRunFailedErrors)}
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/hansbogert/gori"
+)
+
+var remoteHosts []string
+
+// remoteBinary is where gori uploads itself on a host without gori
+const remoteBinary = "~/.cache/gori/gori-remote"
+
+// remoteHost is a scan root on another machine, user@server:/srv/repos
+type remoteHost struct {
+	// destination is what ssh connects to, e.g. user@server
+	destination string
+	// path is the scan root on the host, the login directory when empty
+	path string
+}
+
+func (h remoteHost) String() string {
+	return h.destination + ":" + h.path
+}
+
+// parseRemoteHost parses user@server:/srv/repos, the path being optional. A
+// destination starting with - is refused, as ssh would take it for an option.
+func parseRemoteHost(spec string) (remoteHost, error) {
+	destination, path, _ := strings.Cut(spec, ":")
+	if destination == "" || strings.HasPrefix(destination, "-") {
+		return remoteHost{}, fmt.Errorf("invalid host %q, use user@server:/path", spec)
+	}
+	return remoteHost{destination: destination, path: path}, nil
+}
+
+// sshCommand is the ssh client, GORI_SSH overrides it like GIT_SSH does for
+// git
+func sshCommand() string {
+	if ssh := os.Getenv("GORI_SSH"); ssh != "" {
+		return ssh
+	}
+	return "ssh"
+}
+
+// ssh runs the shell command on the host, feeding it stdin
+func ssh(host remoteHost, command string, stdin []byte) ([]byte, error) {
+	cmd := exec.Command(sshCommand(), "-o", "BatchMode=yes", "--", host.destination, command)
+	cmd.Stdin = bytes.NewReader(stdin)
+	cmd.Stderr = os.Stderr
+	return cmd.Output()
+}
+
+// shellQuote quotes s for a POSIX shell, leaving ~/ at the start to be
+// expanded
+func shellQuote(s string) string {
+	if rest, ok := strings.CutPrefix(s, "~/"); ok {
+		return "~/" + shellQuote(rest)
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// remoteRecords scans the host's scan root with the gori installed there, or
+// else with a copy of this gori when the host runs the same platform. The
+// records are marked with the host.
+func remoteRecords(host remoteHost) ([]repoRecord, error) {
+	args := "status --format json --concurrency " + shellQuote(concurrencyFlag)
+	if host.path != "" {
+		args += " " + shellQuote(host.path)
+	}
+	script := fmt.Sprintf(`if command -v gori >/dev/null; then gori %[1]s; elif [ -x %[2]s ]; then %[2]s %[1]s; else exit 127; fi`, args, remoteBinary)
+
+	out, err := ssh(host, script, nil)
+	if exitErr := (*exec.ExitError)(nil); errors.As(err, &exitErr) && exitErr.ExitCode() == 127 {
+		if err := uploadGori(host); err != nil {
+			return nil, fmt.Errorf("%s has no gori: %w", host.destination, err)
+		}
+		out, err = ssh(host, script, nil)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("scanning %s: %w", host, err)
+	}
+
+	var records []repoRecord
+	if err := json.Unmarshal(out, &records); err != nil {
+		return nil, fmt.Errorf("reading the scan of %s: %w", host, err)
+	}
+	for i := range records {
+		records[i].Host = host.destination
+	}
+	return records, nil
+}
+
+// uploadGori copies this gori to the host, which must run the same platform
+func uploadGori(host remoteHost) error {
+	out, err := ssh(host, "uname -sm", nil)
+	if err != nil {
+		return fmt.Errorf("detecting its platform: %w", err)
+	}
+	platform := unamePlatform(string(out))
+	if platform != runtime.GOOS+"/"+runtime.GOARCH {
+		return fmt.Errorf("install it there, this gori is for %s/%s and the host runs %s", runtime.GOOS, runtime.GOARCH, platform)
+	}
+
+	binary, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	content, err := os.ReadFile(binary)
+	if err != nil {
+		return err
+	}
+	_, err = ssh(host, fmt.Sprintf("mkdir -p ~/.cache/gori && cat > %[1]s && chmod +x %[1]s", remoteBinary), content)
+	return err
+}
+
+// unamePlatform turns the output of uname -sm into a GOOS/GOARCH pair
+func unamePlatform(uname string) string {
+	system, machine, _ := strings.Cut(strings.TrimSpace(uname), " ")
+	goarch := map[string]string{
+		"x86_64":  "amd64",
+		"amd64":   "amd64",
+		"aarch64": "arm64",
+		"arm64":   "arm64",
+		"i686":    "386",
+		"armv7l":  "arm",
+	}[machine]
+	return strings.ToLower(system) + "/" + cmp.Or(goarch, machine)
+}
+
+// hostRecords scans the remote hosts, and the local scan root when one is
+// given, one after the other. A host which cannot be scanned becomes a record
+// with its error, so the others are still reported.
+func hostRecords(hosts []string, args []string) ([]repoRecord, error) {
+	var records []repoRecord
+	if len(args) > 0 {
+		local, err := repoRecords(scanPathArg(args))
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, local...)
+	}
+	for _, spec := range hosts {
+		host, err := parseRemoteHost(spec)
+		if err != nil {
+			return nil, err
+		}
+		remote, err := remoteRecords(host)
+		if err != nil {
+			records = append(records, repoRecord{Host: host.destination, Path: cmp.Or(host.path, "~"), Error: err.Error()})
+			continue
+		}
+		records = append(records, remote...)
+	}
+	return records, nil
+}
+
+// writeTextRecords lists the records which fail a check the way gori status
+// shows its projects, grouped by host, each group followed by its errors
+func writeTextRecords(w io.Writer, records []repoRecord) {
+	for i, group := range groupByHost(records) {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		var lines []string
+		problems := repoProblems{}
+		for _, record := range group {
+			if record.Error != "" {
+				problems[record.Path] = append(problems[record.Path], record.Error)
+				continue
+			}
+			project := gori.ProjectStatus{
+				Path:         record.Path,
+				IsDirty:      record.Dirty,
+				HasStash:     record.Stash,
+				Stashes:      record.Stashes,
+				Upstreamed:   record.Upstreamed,
+				Orphaned:     record.Orphaned,
+				Diverged:     record.Diverged,
+				DivergedTags: record.DivergedTags,
+				CI:           record.CI,
+			}
+			if !project.Clean() || project.CI == gori.CIFailed {
+				lines = append(lines, projectStatusLine(project))
+			}
+		}
+		fmt.Fprintf(w, "%s: %d of %d need attention\n", cmp.Or(group[0].Host, "localhost"), len(lines), len(group)-len(problems))
+		for _, line := range lines {
+			fmt.Fprintf(w, "  %s\n", line)
+		}
+		problems.print(w)
+	}
+}
+
+// groupByHost splits the records by host, keeping their order
+func groupByHost(records []repoRecord) [][]repoRecord {
+	var groups [][]repoRecord
+	index := map[string]int{}
+	for _, record := range records {
+		i, ok := index[record.Host]
+		if !ok {
+			i = len(groups)
+			index[record.Host] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], record)
+	}
+	return groups
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/hansbogert/gori"
+)
+
+var standup bool
+var standupDays int
+
+// newReportCmd builds the command writing reports meant for people rather
+// than scripts
+func newReportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "report [path]",
+		Short:             "Write a Markdown report of the work in progress, like a standup digest with --standup",
+		RunE:              runReport,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeScanRoot,
+	}
+	cmd.Flags().BoolVar(&standup, "standup", false, "list the repositories with work which is not upstreamed or not committed, touched in the last --days, with their recent commits")
+	cmd.Flags().IntVar(&standupDays, "days", 1, "how many days back --standup looks")
+	return cmd
+}
+
+func runReport(cmd *cobra.Command, args []string) error {
+	if !standup {
+		return fmt.Errorf("choose a report, e.g. --standup")
+	}
+	if standupDays < 1 {
+		return fmt.Errorf("--days must be at least 1, got %d", standupDays)
+	}
+	scanPath := scanPathArg(args)
+	displayRoot = scanPath
+	since := time.Now().AddDate(0, 0, -standupDays)
+
+	ignoreConfig, err := gori.LoadMergedIgnoreConfig(scanPath)
+	if err != nil {
+		slog.Warn("loading ignore config", "err", err)
+	}
+	repoPaths, err := discoverRepos(scanPath)
+	if err != nil {
+		return err
+	}
+
+	var items []standupItem
+	problems := repoProblems{}
+	scanProjects(repoPaths, scanPath, ignoreConfig, func(result repoResult) {
+		if result.err != nil {
+			problems.add(result)
+			return
+		}
+		if item, ok := newStandupItem(result.status, since); ok {
+			items = append(items, item)
+		}
+	})
+	problems.print(os.Stderr)
+
+	writeStandup(os.Stdout, items, standupDays)
+	return nil
+}
+
+// standupItem is a repository with work in progress, as listed by the standup
+// report
+type standupItem struct {
+	name          string
+	branch        string
+	notUpstreamed bool
+	// changedFiles is how many files have uncommitted changes
+	changedFiles int
+	// commits are the subjects of the recent commits on the branch
+	commits []string
+}
+
+// newStandupItem returns the standup item of the project, when it is not
+// upstreamed or dirty and was touched since: committed to, or a changed file
+// was modified
+func newStandupItem(project gori.ProjectStatus, since time.Time) (standupItem, bool) {
+	if project.Upstreamed && !project.IsDirty {
+		return standupItem{}, false
+	}
+	item := standupItem{name: displayName(project.Path), notUpstreamed: !project.Upstreamed}
+	if project.IsDirty {
+		item.changedFiles = len(changedFiles(project.StatusString))
+	}
+	if recent, ok := backendFor(project.Path).(recentBackend); ok {
+		item.branch, item.commits = recent.RecentCommits(project.Path, since)
+	}
+
+	touched := project.LastCommit.After(since) || len(item.commits) > 0
+	if !touched && project.IsDirty {
+		touched = changedSince(project.Path, changedFiles(project.StatusString), since)
+	}
+	return item, touched
+}
+
+// changedFiles returns the paths of the changed files listed in the changes
+// of a backend, a line per file with the path last
+func changedFiles(changes string) []string {
+	var files []string
+	for line := range strings.Lines(changes) {
+		if fields := strings.Fields(line); len(fields) > 1 {
+			files = append(files, fields[len(fields)-1])
+		}
+	}
+	return files
+}
+
+// changedSince reports whether one of the files in the repository was
+// modified since the time, deleted ones do not tell
+func changedSince(repoPath string, files []string, since time.Time) bool {
+	for _, file := range files {
+		if info, err := os.Stat(filepath.Join(repoPath, file)); err == nil && info.ModTime().After(since) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeStandup writes the items as a Markdown list, ready to paste into a
+// standup note
+func writeStandup(w io.Writer, items []standupItem, days int) {
+	fmt.Fprintf(w, tr("## Work in progress, last %d day(s)\n\n"), days)
+	if len(items) == 0 {
+		fmt.Fprintln(w, tr("Nothing in progress."))
+		return
+	}
+	for _, item := range items {
+		var states []string
+		if item.notUpstreamed {
+			states = append(states, tr("not upstreamed"))
+		}
+		if item.changedFiles > 0 {
+			states = append(states, fmt.Sprintf(tr("%d uncommitted file(s)"), item.changedFiles))
+		}
+		branch := ""
+		if item.branch != "" {
+			branch = fmt.Sprintf(" (`%s`)", item.branch)
+		}
+		fmt.Fprintf(w, "- **%s**%s: %s\n", item.name, branch, strings.Join(states, ", "))
+		for _, commit := range item.commits {
+			fmt.Fprintf(w, "  - %s\n", commit)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	git "github.com/go-git/go-git/v5"
+)
+
+var runLog string
+
+// runRecord is the summary of a run in the run log, one JSON line each
+type runRecord struct {
+	Time time.Time `json:"time"`
+	Root string    `json:"root"`
+	// Repos is the number of repositories checked, the others count those
+	// failing a check after snoozes, and the ones which could not be checked
+	Repos         int `json:"repos"`
+	Dirty         int `json:"dirty"`
+	Stash         int `json:"stash"`
+	NotUpstreamed int `json:"not_upstreamed"`
+	Snoozed       int `json:"snoozed"`
+	Errors        int `json:"errors"`
+	// Seconds is how long checking the repositories took
+	Seconds float64 `json:"seconds"`
+}
+
+// thisRun tallies the repositories checked by this run
+var thisRun runRecord
+
+// tally counts the result in this run's summary. Directories which are not a
+// repository do not count.
+func (r *runRecord) tally(result repoResult) {
+	switch {
+	case errors.Is(result.err, git.ErrRepositoryNotExists):
+		return
+	case result.err != nil:
+		r.Errors++
+	default:
+		r.Dirty += count(result.status.IsDirty)
+		r.Stash += count(result.status.HasStash)
+		r.NotUpstreamed += count(!result.status.Upstreamed)
+		r.Snoozed += count(result.status.Snoozed())
+	}
+	r.Repos++
+}
+
+func count(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// logRun appends this run's summary to the run log. Failing to do so only
+// warns, the run itself already happened.
+func logRun(logFile string, scanPath string) {
+	if strings.HasPrefix(logFile, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			logFile = filepath.Join(home, logFile[2:])
+		}
+	}
+	thisRun.Time = time.Now()
+	thisRun.Root, _ = filepath.Abs(scanPath)
+	if err := appendRunLog(logFile, thisRun); err != nil {
+		slog.Warn("writing run log", "err", err)
+	}
+}
+
+// summarizeRun sends this run's summary to the summary logger
+func summarizeRun(scanPath string) {
+	root, _ := filepath.Abs(scanPath)
+	summaryLogger.Info("run finished", "root", root, "repos", thisRun.Repos,
+		"dirty", thisRun.Dirty, "stash", thisRun.Stash, "not_upstreamed", thisRun.NotUpstreamed,
+		"snoozed", thisRun.Snoozed, "errors", thisRun.Errors, "seconds", thisRun.Seconds)
+}
+
+func appendRunLog(logFile string, record runRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(logFile), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(logFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/rogpeppe/go-internal/testscript"
+)
+
+func TestMain(m *testing.M) {
+	commands := map[string]func() int{
+		"gori": Main,
+		"git": func() int {
+			cmd := exec.Command("/usr/bin/git", os.Args[1:]...)
+			cmd.Stdin = os.Stdin
+			cmd.Stdout = os.Stdout
+			cmd.Stderr = os.Stderr
+			if err := cmd.Run(); err != nil {
+				if exitError, ok := err.(*exec.ExitError); ok {
+					return exitError.ExitCode()
+				}
+				return 1
+			}
+			return 0
+		},
+	}
+	os.Exit(testscript.RunMain(m, commands))
+}
+
+func TestGori(t *testing.T) {
+	testscript.Run(t, testscript.Params{
+		Dir: "../../test",
+		Setup: func(env *testscript.Env) error {
+			// git config of the machine running the tests stays out
+			env.Setenv("HOME", env.WorkDir)
+			env.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+			return nil
+		},
+		Cmds: map[string]func(ts *testscript.TestScript, neg bool, args []string){
+			"chtimes": chtimes,
+		},
+	})
+}
+
+// chtimes sets the modification time of a file, so scripts do not depend on
+// the clock: chtimes path 2001-01-01T00:00:00Z
+func chtimes(ts *testscript.TestScript, neg bool, args []string) {
+	if neg || len(args) != 2 {
+		ts.Fatalf("usage: chtimes path time")
+	}
+	t, err := time.Parse(time.RFC3339, args[1])
+	ts.Check(err)
+	ts.Check(os.Chtimes(ts.MkAbs(args[0]), t, t))
+}
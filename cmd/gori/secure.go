@@ -0,0 +1,145 @@
+package main
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/hansbogert/gori"
+)
+
+var secureRemote string
+var secureNamespace string
+
+func newSecureCmd() *cobra.Command {
+	secureCmd := &cobra.Command{
+		Use:               "secure [path]",
+		Short:             "Push a backup of every flagged repository, uncommitted work included, to a backup namespace",
+		RunE:              runSecure,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeScanRoot,
+	}
+	secureCmd.Flags().BoolVarP(&dryRun, "dry-run", "n", false, "only show where each repository would be backed up")
+	secureCmd.Flags().StringVar(&secureRemote, "remote", "", "remote to push the backups to (default from gori.cue or origin)")
+	secureCmd.Flags().StringVar(&secureNamespace, "namespace", "", "references the backups go under on the remote (default from gori.cue or refs/gori/backup/)")
+	return secureCmd
+}
+
+// runSecure backs up every flagged git repository: the checked out branch
+// with a WIP commit of the uncommitted work on top is pushed to
+// <namespace><hostname>/<directory>-<hash>/<branch> on the remote, replacing
+// the previous backup. The hash of the repository's absolute path keeps two
+// clones with the same directory name apart.
+// The working directory, the index and the local branches stay as they are.
+func runSecure(cmd *cobra.Command, args []string) error {
+	scanPath := scanPathArg(args)
+	settings := loadSettings()
+	remote := cmp.Or(secureRemote, settings.BackupRemote, "origin")
+	namespace := cmp.Or(secureNamespace, settings.BackupNamespace, "refs/gori/backup/")
+	if !strings.HasPrefix(namespace, "refs/") {
+		return fmt.Errorf("backup namespace %q does not start with refs/", namespace)
+	}
+	namespace = strings.TrimSuffix(namespace, "/") + "/"
+	hostname, err := os.Hostname()
+	if err != nil {
+		return fmt.Errorf("getting the hostname: %w", err)
+	}
+
+	flagged, err := selectRepos(scanPath, false, nil)
+	if err != nil {
+		return err
+	}
+	var repoPaths []string
+	for _, repoPath := range flagged {
+		if _, err := gori.OpenRepo(repoPath); err == nil {
+			repoPaths = append(repoPaths, repoPath)
+		}
+	}
+
+	label := "gori secure " + time.Now().Format(time.DateTime)
+	errs := forEachRepo(repoPaths, func(repoPath string) error {
+		branch, err := gitOutput(repoPath, "symbolic-ref", "--quiet", "--short", "HEAD")
+		if err != nil {
+			branch = "detached"
+		}
+		ref := namespace + hostname + "/" + backupDir(repoPath) + "/" + strings.TrimSpace(branch)
+		if dryRun {
+			fmt.Printf("%s: %s %s\n", filepath.Base(repoPath), remote, ref)
+			return nil
+		}
+
+		commit, err := backupCommit(repoPath, label)
+		if err != nil {
+			return err
+		}
+		if _, err := gitOutput(repoPath, "push", "--quiet", "--force", remote, commit+":"+ref); err != nil {
+			return err
+		}
+		fmt.Printf("%s: pushed to %s %s\n", filepath.Base(repoPath), remote, ref)
+		return nil
+	})
+
+	if len(errs) > 0 {
+		fmt.Println("\nFailed to back up:")
+		for _, repoPath := range slices.Sorted(maps.Keys(errs)) {
+			fmt.Printf("  %s: %s\n", filepath.Base(repoPath), errs[repoPath])
+		}
+		return fmt.Errorf("backing up failed in %d of %d repositories", len(errs), len(repoPaths))
+	}
+	return nil
+}
+
+// backupDir returns the directory name of the repository with a short hash of
+// its absolute path, e.g. api-1a2b3c4d, naming its backups
+func backupDir(repoPath string) string {
+	sum := sha256.Sum256([]byte(gori.AbsPath(repoPath)))
+	return filepath.Base(repoPath) + "-" + hex.EncodeToString(sum[:4])
+}
+
+// backupCommit returns the commit to back up: HEAD, or with uncommitted work a
+// "WIP: label" commit of it, untracked files included, on top of HEAD. The
+// commit is made with an index of its own, so the real one is left alone.
+func backupCommit(repoPath, label string) (string, error) {
+	head, err := gitOutput(repoPath, "rev-parse", "--verify", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	head = strings.TrimSpace(head)
+	if changes, err := gitOutput(repoPath, "status", "--porcelain"); err != nil || changes == "" {
+		return head, err
+	}
+
+	index, err := os.CreateTemp("", "gori-index-")
+	if err != nil {
+		return "", err
+	}
+	index.Close()
+	// git creates the index itself, an empty file is not one
+	os.Remove(index.Name())
+	defer os.Remove(index.Name())
+	env := []string{"GIT_INDEX_FILE=" + index.Name()}
+
+	for _, step := range [][]string{{"read-tree", "HEAD"}, {"add", "--all"}} {
+		if _, err := gitOutputEnv(repoPath, env, step...); err != nil {
+			return "", err
+		}
+	}
+	tree, err := gitOutputEnv(repoPath, env, "write-tree")
+	if err != nil {
+		return "", err
+	}
+	commit, err := gitOutput(repoPath, "commit-tree", strings.TrimSpace(tree), "-p", head, "-m", "WIP: "+label)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(commit), nil
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// releasesURL is the GitHub API endpoint describing gori's latest release
+var releasesURL = "https://api.github.com/repos/hansbogert/gori/releases/latest"
+
+// checksumsAsset is the release asset listing the sha256 sums of the binaries,
+// in the format of sha256sum
+const checksumsAsset = "checksums.txt"
+
+var updateCheckOnly bool
+var updateForce bool
+
+// release is the part of a GitHub release gori needs to update itself
+type release struct {
+	Tag    string         `json:"tag_name"`
+	Assets []releaseAsset `json:"assets"`
+}
+
+type releaseAsset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+// asset finds the release asset called name
+func (r *release) asset(name string) (releaseAsset, bool) {
+	for _, asset := range r.Assets {
+		if asset.Name == name {
+			return asset, true
+		}
+	}
+	return releaseAsset{}, false
+}
+
+// newSelfUpdateCmd builds the command replacing the running binary with the
+// latest release
+func newSelfUpdateCmd() *cobra.Command {
+	selfUpdateCmd := &cobra.Command{
+		Use:   "self-update",
+		Short: "Replace gori with the latest release from GitHub, checked against its checksums",
+		RunE:  runSelfUpdate,
+		Args:  cobra.NoArgs,
+	}
+	selfUpdateCmd.Flags().BoolVar(&updateCheckOnly, "check", false, "only report whether a newer release is available")
+	selfUpdateCmd.Flags().BoolVar(&updateForce, "force", false, "install the latest release even if it is not newer, downgrading gori, or gori was built from source")
+	return selfUpdateCmd
+}
+
+func runSelfUpdate(cmd *cobra.Command, args []string) error {
+	client := &http.Client{Timeout: time.Minute}
+	latest, err := latestRelease(client)
+	if err != nil {
+		return err
+	}
+
+	current := buildVersion()
+	order, comparable := compareVersions(current, latest.Tag)
+	if !comparable && current != latest.Tag {
+		// without versions to compare, another release counts as newer
+		order = -1
+	}
+	switch {
+	case order == 0 && !updateForce:
+		fmt.Printf("gori %s is the latest release.\n", current)
+		return nil
+	case order > 0 && !updateForce:
+		fmt.Printf("gori %s is newer than the latest release %s, use --force to downgrade.\n", current, latest.Tag)
+		return nil
+	case updateCheckOnly:
+		fmt.Printf("gori %s is available, this is %s.\n", latest.Tag, current)
+		return nil
+	case current == devVersion && !updateForce:
+		return fmt.Errorf("this gori was built from source, update it the same way or use --force to install %s", latest.Tag)
+	}
+
+	binary, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("finding the gori binary: %w", err)
+	}
+	if binary, err = filepath.EvalSymlinks(binary); err != nil {
+		return fmt.Errorf("finding the gori binary: %w", err)
+	}
+	if err := installRelease(client, latest, assetName(runtime.GOOS, runtime.GOARCH), binary); err != nil {
+		return err
+	}
+	fmt.Printf("Updated %s from %s to %s.\n", binary, current, latest.Tag)
+	return nil
+}
+
+// compareVersions compares two semantic versions like v1.2.3 and v1.3.0-rc.1,
+// reporting false when either is not one, e.g. dev
+func compareVersions(a, b string) (int, bool) {
+	aCore, aPre, aOK := parseVersion(a)
+	bCore, bPre, bOK := parseVersion(b)
+	if !aOK || !bOK {
+		return 0, false
+	}
+	if order := slices.Compare(aCore, bCore); order != 0 {
+		return order, true
+	}
+	// a pre-release comes before its release
+	if aPre == "" || bPre == "" {
+		return cmp.Compare(count(aPre == ""), count(bPre == "")), true
+	}
+	aIDs, bIDs := strings.Split(aPre, "."), strings.Split(bPre, ".")
+	for i := 0; i < len(aIDs) && i < len(bIDs); i++ {
+		aNum, aErr := strconv.Atoi(aIDs[i])
+		bNum, bErr := strconv.Atoi(bIDs[i])
+		var order int
+		switch {
+		case aErr == nil && bErr == nil:
+			order = cmp.Compare(aNum, bNum)
+		case aErr == nil || bErr == nil:
+			// numeric identifiers come before alphanumeric ones
+			order = cmp.Compare(count(aErr != nil), count(bErr != nil))
+		default:
+			order = cmp.Compare(aIDs[i], bIDs[i])
+		}
+		if order != 0 {
+			return order, true
+		}
+	}
+	return cmp.Compare(len(aIDs), len(bIDs)), true
+}
+
+// parseVersion splits v1.2.3-rc.1+build into its major, minor and patch, and
+// its pre-release
+func parseVersion(version string) ([]int, string, bool) {
+	rest, ok := strings.CutPrefix(version, "v")
+	if !ok {
+		return nil, "", false
+	}
+	rest, _, _ = strings.Cut(rest, "+")
+	rest, pre, _ := strings.Cut(rest, "-")
+	parts := strings.Split(rest, ".")
+	if len(parts) != 3 {
+		return nil, "", false
+	}
+	core := make([]int, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, "", false
+		}
+		core[i] = n
+	}
+	return core, pre, true
+}
+
+// assetName is the name of the release asset holding the binary for the
+// given platform
+func assetName(goos, goarch string) string {
+	name := fmt.Sprintf("gori_%s_%s", goos, goarch)
+	if goos == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// latestRelease asks GitHub for the latest release of gori
+func latestRelease(client *http.Client) (*release, error) {
+	body, err := download(client, releasesURL)
+	if err != nil {
+		return nil, fmt.Errorf("looking up the latest release: %w", err)
+	}
+	var latest release
+	if err := json.Unmarshal(body, &latest); err != nil {
+		return nil, fmt.Errorf("looking up the latest release: %w", err)
+	}
+	if latest.Tag == "" {
+		return nil, fmt.Errorf("looking up the latest release: no tag in the response")
+	}
+	return &latest, nil
+}
+
+// installRelease downloads the release's binary called name, checks it
+// against the release's checksums and only then replaces binary with it. The
+// checksums come from the same release, so they catch a corrupted download,
+// not a tampered release.
+func installRelease(client *http.Client, latest *release, name, binary string) error {
+	asset, ok := latest.asset(name)
+	if !ok {
+		return fmt.Errorf("release %s has no binary %s for this platform", latest.Tag, name)
+	}
+	sums, ok := latest.asset(checksumsAsset)
+	if !ok {
+		return fmt.Errorf("release %s has no %s, refusing to install an unverified binary", latest.Tag, checksumsAsset)
+	}
+
+	checksums, err := download(client, sums.URL)
+	if err != nil {
+		return fmt.Errorf("downloading %s: %w", checksumsAsset, err)
+	}
+	want, err := findChecksum(checksums, name)
+	if err != nil {
+		return err
+	}
+	content, err := download(client, asset.URL)
+	if err != nil {
+		return fmt.Errorf("downloading %s: %w", name, err)
+	}
+	sum := sha256.Sum256(content)
+	if got := hex.EncodeToString(sum[:]); got != want {
+		return fmt.Errorf("checksum mismatch for %s: got %s, want %s", name, got, want)
+	}
+	return replaceBinary(binary, content)
+}
+
+// findChecksum looks up the sha256 sum of name in the output of sha256sum
+func findChecksum(checksums []byte, name string) (string, error) {
+	scanner := bufio.NewScanner(strings.NewReader(string(checksums)))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	return "", fmt.Errorf("%s has no checksum for %s", checksumsAsset, name)
+}
+
+// replaceBinary writes content next to binary and renames it into place, so
+// the binary is never left half written
+func replaceBinary(binary string, content []byte) error {
+	info, err := os.Stat(binary)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(binary), ".gori-update-*")
+	if err != nil {
+		return fmt.Errorf("replacing %s: %w", binary, err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return fmt.Errorf("replacing %s: %w", binary, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("replacing %s: %w", binary, err)
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()|0111); err != nil {
+		return fmt.Errorf("replacing %s: %w", binary, err)
+	}
+	if err := os.Rename(tmp.Name(), binary); err != nil {
+		return fmt.Errorf("replacing %s: %w", binary, err)
+	}
+	return nil
+}
+
+// download fetches url, failing on anything but 200 OK
+func download(client *http.Client, url string) ([]byte, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
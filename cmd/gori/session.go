@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	git "github.com/go-git/go-git/v5"
+
+	"github.com/hansbogert/gori"
+)
+
+var resumeSession bool
+
+// visitSession is where visiting the projects of a scan root got to, saved
+// whenever the next project is visited so that a session left with (q)uit, or
+// cut short by a crash, can be resumed
+type visitSession struct {
+	Root string `json:"root"`
+	// Queue are the projects to visit in order, relative to the root
+	Queue []string `json:"queue"`
+	// Position is the index in Queue of the project being visited
+	Position int       `json:"position"`
+	SavedAt  time.Time `json:"saved_at"`
+}
+
+// sessionFile returns where the visit session of the scan root is saved
+func sessionFile(scanPath string) (string, error) {
+	absRoot, err := filepath.Abs(scanPath)
+	if err != nil {
+		return "", err
+	}
+	return scanRootCacheFile(absRoot, "sessions", ".json")
+}
+
+// saveSession saves the position in the queue of projects. Failing to do so
+// only loses the session, so it is merely logged.
+func saveSession(scanPath string, projects []gori.ProjectStatus, position int) {
+	if err := writeSession(scanPath, projects, position); err != nil {
+		slog.Info("saving visit session", "err", err)
+	}
+}
+
+func writeSession(scanPath string, projects []gori.ProjectStatus, position int) error {
+	file, err := sessionFile(scanPath)
+	if err != nil {
+		return err
+	}
+	session := visitSession{Root: gori.AbsPath(scanPath), Position: position, SavedAt: time.Now()}
+	for _, project := range projects {
+		session.Queue = append(session.Queue, gori.NewPathResolver(scanPath).Rel(project.Path))
+	}
+	b, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	return writeFileAtomic(file, b)
+}
+
+// clearSession forgets the session of the scan root, once every project of it
+// was visited
+func clearSession(scanPath string) {
+	file, err := sessionFile(scanPath)
+	if err != nil {
+		return
+	}
+	if err := os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+		slog.Info("removing visit session", "err", err)
+	}
+}
+
+// loadSession returns the saved session of the scan root
+func loadSession(scanPath string) (*visitSession, error) {
+	file, err := sessionFile(scanPath)
+	if err != nil {
+		return nil, err
+	}
+	b, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf(tr("no visit session of %s to resume"), scanPath)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var session visitSession
+	if err := json.Unmarshal(b, &session); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", file, err)
+	}
+	return &session, nil
+}
+
+// resumedProjects checks the projects the saved session of the scan root had
+// yet to visit, from the one it was at, again. Those which are clean or
+// acknowledged by now are left out.
+func resumedProjects(scanPath string) ([]gori.ProjectStatus, error) {
+	session, err := loadSession(scanPath)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, name := range session.Queue[min(session.Position, len(session.Queue)):] {
+		paths = append(paths, filepath.Join(scanPath, name))
+	}
+	fmt.Printf(tr("Resuming the visit of %s from %s, %d project(s) left.\n"), scanPath, session.SavedAt.Format(time.DateTime), len(paths))
+
+	ignoreConfig, err := gori.LoadMergedIgnoreConfig(scanPath)
+	if err != nil {
+		slog.Warn("loading ignore config", "err", err)
+	}
+	acks, err := readAcks()
+	if err != nil {
+		slog.Warn("loading acknowledgements", "err", err)
+	}
+	var projects []gori.ProjectStatus
+	problems := repoProblems{}
+	scanProjects(paths, scanPath, ignoreConfig, func(result repoResult) {
+		switch {
+		// moved or removed since
+		case errors.Is(result.err, git.ErrRepositoryNotExists):
+		case result.err != nil:
+			problems.add(result)
+		case !result.status.Clean() && !acked(acks, result.status):
+			projects = append(projects, result.status)
+		}
+	})
+	problems.print(os.Stdout)
+	return projects, nil
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/hansbogert/gori"
+)
+
+var snoozePath string
+var snoozeFor string
+var snoozeCheck string
+var pruneAfter string
+var snoozeReason string
+var logRepo string
+
+// newSnoozeCmd groups the commands managing the snoozes of the ignore file
+func newSnoozeCmd() *cobra.Command {
+	snoozeCmd := &cobra.Command{
+		Use:   "snooze",
+		Short: "Manage the snoozes in the scan root's ignore file",
+	}
+	snoozeCmd.PersistentFlags().StringVarP(&snoozePath, "path", "p", "./", "directory holding the ignore file")
+	snoozeCmd.PersistentFlags().BoolVarP(&dryRun, "dry-run", "n", false, "print the changes to the ignore file instead of writing it")
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List every snooze with its expiry",
+		RunE:  runSnoozeList,
+		Args:  cobra.NoArgs,
+	}
+
+	rmCmd := &cobra.Command{
+		Use:               "rm <repo> [check]",
+		Short:             "Remove snoozes, repo may be a glob like 'forks/*'",
+		RunE:              runSnoozeRm,
+		Args:              cobra.RangeArgs(1, 2),
+		ValidArgsFunction: completeSnoozed,
+	}
+	rmCmd.Flags().StringVar(&snoozeReason, "reason", "", "why the snoozes are removed, recorded in the snooze log")
+
+	addCmd := &cobra.Command{
+		Use:               "add <repo>",
+		Short:             "Snooze a check of a repository without scanning",
+		RunE:              runSnoozeAdd,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeSnoozeRepo,
+	}
+	addCmd.Flags().StringVar(&snoozeFor, "for", "", "how long to snooze, e.g. 1h, 2d, 3w, 4m or 5y (default from gori.cue)")
+	addCmd.Flags().StringVar(&snoozeCheck, "check", "", fmt.Sprintf("check to snooze, one of %v (default from gori.cue or all)", gori.ValidChecks))
+	addCmd.Flags().StringVar(&snoozeReason, "reason", "", "why the check is snoozed, recorded in the snooze log")
+	_ = addCmd.RegisterFlagCompletionFunc("check", completeValues(gori.ValidChecks))
+
+	allCmd := &cobra.Command{
+		Use:   "all",
+		Short: "Snooze every repository currently failing a check, e.g. before a vacation",
+		RunE:  runSnoozeAll,
+		Args:  cobra.NoArgs,
+	}
+	allCmd.Flags().StringVar(&snoozeFor, "for", "", "how long to snooze, e.g. 1h, 2d, 3w, 4m or 5y (default from gori.cue)")
+	allCmd.Flags().StringVar(&snoozeCheck, "check", "", fmt.Sprintf("check to snooze, one of %v (default from gori.cue or all)", gori.ValidChecks))
+	allCmd.Flags().StringVar(&snoozeReason, "reason", "", "why the checks are snoozed, recorded in the snooze log")
+	_ = allCmd.RegisterFlagCompletionFunc("check", completeValues(gori.ValidChecks))
+
+	pruneCmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Remove long expired snoozes and those of repositories which no longer exist",
+		RunE:  runSnoozePrune,
+		Args:  cobra.NoArgs,
+	}
+	pruneCmd.Flags().StringVar(&pruneAfter, "after", "", "how long after expiry snoozes are pruned (default from gori.cue or 30d)")
+
+	logCmd := &cobra.Command{
+		Use:   "log",
+		Short: "Show the history of snooze changes, oldest first",
+		RunE:  runSnoozeLog,
+		Args:  cobra.NoArgs,
+	}
+	logCmd.Flags().StringVar(&logRepo, "repo", "", "only show the changes of repos matching this glob")
+
+	// the commands writing the ignore file hold the lock of its directory
+	for _, cmd := range []*cobra.Command{addCmd, allCmd, rmCmd, pruneCmd} {
+		locksScanRootOf("path", cmd)
+	}
+	snoozeCmd.AddCommand(addCmd, allCmd, listCmd, rmCmd, pruneCmd, logCmd)
+	return snoozeCmd
+}
+
+func runSnoozeList(cmd *cobra.Command, args []string) error {
+	config, err := gori.LoadIgnoreConfig(snoozePath)
+	if errors.Is(err, os.ErrNotExist) {
+		fmt.Println(tr("Nothing is snoozed."))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	entries := gori.SnoozeEntries(config)
+	if len(entries) == 0 {
+		fmt.Println(tr("Nothing is snoozed."))
+		return nil
+	}
+
+	w := newTable(os.Stdout)
+	fmt.Fprintln(w, "REPO\tCHECK\tEXPIRES\tREMAINING")
+	for _, entry := range entries {
+		expires, remaining := "invalid", "?"
+		if !entry.Until.IsZero() {
+			expires = entry.Until.Format(time.DateTime)
+			if entry.Expired() {
+				remaining = "expired " + gori.FormatDuration(time.Since(entry.Until)) + " ago"
+			} else {
+				remaining = gori.FormatDuration(time.Until(entry.Until))
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", entry.Repo, entry.Check, expires, remaining)
+	}
+	return w.Flush()
+}
+
+func runSnoozeRm(cmd *cobra.Command, args []string) error {
+	check := "all"
+	if len(args) > 1 {
+		check = args[1]
+	}
+
+	removed, err := gori.RemoveSnoozes(snoozePath, args[0], check, snoozeReason, dryRun)
+	if err != nil {
+		return err
+	}
+	fmt.Printf(tr("Removed %d snooze(s).\n"), removed)
+	return nil
+}
+
+func runSnoozeAdd(cmd *cobra.Command, args []string) error {
+	repoPath := filepath.Join(snoozePath, args[0])
+	if info, err := os.Stat(repoPath); err != nil || !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", repoPath)
+	}
+
+	duration, check, err := snoozeDefaults(loadSettings())
+	if err != nil {
+		return err
+	}
+
+	if err := gori.AddSnooze(snoozePath, repoPath, duration, check, snoozeReason, dryRun); err != nil {
+		return err
+	}
+	fmt.Printf(tr("Snoozed %s of %s for %s.\n"), check, args[0], duration)
+	return nil
+}
+
+func runSnoozeAll(cmd *cobra.Command, args []string) error {
+	duration, check, err := snoozeDefaults(loadSettings())
+	if err != nil {
+		return err
+	}
+
+	projects, err := flaggedProjects(snoozePath, false)
+	if err != nil {
+		return err
+	}
+	if len(projects) == 0 {
+		fmt.Println(tr("Nothing to snooze."))
+		return nil
+	}
+
+	var paths []string
+	for _, project := range projects {
+		paths = append(paths, project.Path)
+	}
+	if err := gori.AddSnoozes(snoozePath, paths, duration, check, snoozeReason, dryRun); err != nil {
+		return err
+	}
+	for _, project := range projects {
+		fmt.Printf(tr("Snoozed %s of %s for %s.\n"), check, filepath.Base(project.Path), duration)
+	}
+	return nil
+}
+
+// snoozeDefaults returns the duration and check to snooze, from the flags or
+// the settings
+func snoozeDefaults(settings *gori.Settings) (string, string, error) {
+	duration := cmp.Or(snoozeFor, settings.SnoozeDuration)
+	check := cmp.Or(snoozeCheck, settings.SnoozeCheck, "all")
+	if duration == "" {
+		return "", "", errors.New("no duration given, use --for or set snooze_duration in gori.cue")
+	}
+	return duration, check, nil
+}
+
+func runSnoozePrune(cmd *cobra.Command, args []string) error {
+	pruned, err := pruneSnoozes(snoozePath, loadSettings(), dryRun)
+	if errors.Is(err, os.ErrNotExist) {
+		fmt.Println(tr("Nothing is snoozed."))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, p := range pruned {
+		fmt.Println(tr("Pruned"), p)
+	}
+	fmt.Printf(tr("Pruned %d snooze(s).\n"), len(pruned))
+	return nil
+}
+
+// pruneSnoozes prunes the stale snoozes of the scan path according to the
+// --after flag or the settings. The entries of repositories found at another
+// path by their origin are kept for --migrate-moved. With dryRun the changes
+// are printed instead.
+func pruneSnoozes(scanPath string, settings *gori.Settings, dryRun bool) ([]string, error) {
+	after, err := gori.ParseSnoozeDuration(cmp.Or(pruneAfter, settings.SnoozePruneAfter, "30d"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid prune duration: %w", err)
+	}
+	repoPaths, err := discoverGitRepos(scanPath)
+	if err != nil {
+		return nil, err
+	}
+	remotes := map[string]string{}
+	for _, repoPath := range repoPaths {
+		remotes[repoPath] = gori.OriginURL(repoPath)
+	}
+	return gori.PruneSnoozes(scanPath, after, remotes, dryRun)
+}
+
+func runSnoozeLog(cmd *cobra.Command, args []string) error {
+	if _, err := path.Match(logRepo, ""); err != nil {
+		return fmt.Errorf("invalid pattern %q: %w", logRepo, err)
+	}
+	entries, err := gori.SnoozeLog(snoozePath)
+	if err != nil {
+		return err
+	}
+	if logRepo != "" {
+		entries = slices.DeleteFunc(entries, func(entry gori.SnoozeLogEntry) bool {
+			matched, _ := path.Match(logRepo, entry.Repo)
+			return !matched
+		})
+	}
+	if len(entries) == 0 {
+		fmt.Println(tr("No snooze changes recorded."))
+		return nil
+	}
+
+	w := newTable(os.Stdout)
+	fmt.Fprintln(w, "TIME\tUSER\tACTION\tREPO\tCHECK\tDURATION\tREASON")
+	for _, entry := range entries {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", entry.Time.Local().Format(time.DateTime), entry.User, entry.Action, entry.Repo, entry.Check, entry.Duration, entry.Reason)
+	}
+	return w.Flush()
+}
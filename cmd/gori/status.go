@@ -0,0 +1,813 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	rtdebug "runtime/debug"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chzyer/readline"
+	git "github.com/go-git/go-git/v5"
+	"github.com/spf13/cobra"
+
+	"github.com/hansbogert/gori"
+)
+
+var showChanges bool
+var sortOrder string
+var displayStyle string
+
+// displayStyles are the ways of showing a repository's name for --display
+var displayStyles = []string{"base", "relative", "full"}
+
+var groupBy string
+
+// groupings are the ways of grouping the results for --group-by
+var groupings = []string{"none", "host", "org"}
+
+// displayRoot is the scan root repositories are shown relative to
+var displayRoot string
+var noVisit bool
+var expiryWindow string
+var showSnoozed bool
+var showAge bool
+var explain bool
+var repoTimeout time.Duration
+
+func newStatusCmd() *cobra.Command {
+	statusCmd := &cobra.Command{
+		Use:               "status [path]",
+		Short:             "Show the repositories failing a check, then offer to visit them",
+		RunE:              runStatus,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeScanRoot,
+	}
+	addStatusFlags(statusCmd)
+	return statusCmd
+}
+
+// addStatusFlags adds the flags of the status command, which are shared by
+// the bare gori invocation
+func addStatusFlags(cmd *cobra.Command) {
+	addVisitFlags(cmd)
+	cmd.Flags().BoolVar(&noVisit, "no-visit", false, "only show the results, do not offer to visit the projects")
+	cmd.Flags().BoolVar(&showSnoozed, "show-snoozed", false, "show snoozed findings dimmed instead of hiding them (default from gori.cue)")
+	cmd.Flags().BoolVar(&explain, "explain", false, "tell why each check fired: the dirty files, the commits which are not upstreamed and the stashes")
+	cmd.Flags().BoolVar(&hyperlinks, "hyperlinks", false, "link repository names to their directory, or with hyperlink_target in gori.cue their remote (default on in terminals supporting it)")
+	cmd.Flags().BoolVar(&showAge, "age", false, "show how long ago the last commit was made, telling fresh work from abandoned work (default from gori.cue)")
+	cmd.Flags().StringVar(&outputFormat, "format", "text", fmt.Sprintf("output format, one of %v; json, porcelain and openmetrics list every repository, org the flagged ones, and they never visit", OutputFormats))
+	cmd.Flags().StringVar(&runLog, "run-log", "", "append a JSON line summing up the run to this file (default from gori.cue)")
+	cmd.Flags().StringVarP(&outputFile, "output", "o", "", "write the report in --format to this file instead of stdout, replacing it at once; never visits")
+	cmd.Flags().StringVar(&reportTemplate, "report-template", "", "render the report through this Go template file instead of --format; never visits")
+	cmd.Flags().BoolVar(&copyReport, "copy", false, "copy the report in --format to the clipboard as well, through the terminal over ssh; never visits")
+	cmd.Flags().BoolVar(&pullRequests, "pull-requests", false, "look up the pull or merge request of branches which are not upstreamed, showing them as in review or, once merged, upstreamed (default from gori.cue)")
+	cmd.Flags().BoolVar(&ciStatus, "ci", false, "show the CI outcome of HEAD, listing pushed repositories with failing CI as well (default from gori.cue)")
+	cmd.Flags().BoolVar(&detectOrphans, "orphans", false, "flag clones whose origin was deleted or archived, asking the hosting provider or the remote (default from gori.cue)")
+	cmd.Flags().BoolVar(&checkTags, "tags", false, "flag tags pointing at another commit than the tag of the same name on origin, asking the remote (default from gori.cue)")
+	cmd.Flags().StringArrayVar(&remoteHosts, "host", nil, "scan user@server:/path over ssh instead, the local path only when given; repeatable")
+	cmd.Flags().StringVar(&dirtyGrace, "dirty-grace", "", "only report working directories dirty for longer than this, going by the oldest change, e.g. 4h (default from gori.cue)")
+	cmd.Flags().BoolVar(&migrateMoved, "migrate-moved", false, "point the snoozes of repositories which moved, found by their origin URL, at their new path")
+	cmd.Flags().StringVar(&expiryWindow, "expiry-window", "", "point out snoozes expiring within this duration, e.g. 3d (default from gori.cue or 3d)")
+	cmd.Flags().StringVar(&groupBy, "group-by", "none", fmt.Sprintf("group the results under a header with subtotals, one of %v: by the host or the host and organization of their origin", groupings))
+	_ = cmd.RegisterFlagCompletionFunc("format", completeValues(OutputFormats))
+	_ = cmd.RegisterFlagCompletionFunc("group-by", completeValues(groupings))
+}
+
+func runStatus(cmd *cobra.Command, args []string) error {
+	settings := loadSettings()
+	if !cmd.Flags().Changed("show-snoozed") {
+		showSnoozed = settings.ShowSnoozed
+	}
+	if !cmd.Flags().Changed("age") {
+		showAge = settings.ShowAge
+	}
+	if !cmd.Flags().Changed("hyperlinks") {
+		// a report written to a file has no use for them
+		hyperlinks = outputFile == "" && supportsHyperlinks()
+	}
+	hyperlinkTarget = settings.HyperlinkTarget
+	if !cmd.Flags().Changed("pull-requests") {
+		pullRequests = settings.PullRequests
+	}
+	if !cmd.Flags().Changed("ci") {
+		ciStatus = settings.CIStatus
+	}
+	if !cmd.Flags().Changed("orphans") {
+		detectOrphans = settings.DetectOrphans
+	}
+	if !cmd.Flags().Changed("tags") {
+		checkTags = settings.CheckTags
+	}
+	if !cmd.Flags().Changed("run-log") {
+		runLog = settings.RunLog
+	}
+
+	scanPath := scanPathArg(args)
+	displayRoot = scanPath
+	if settings.AutoPruneSnoozes {
+		if _, err := pruneSnoozes(scanPath, settings, false); err != nil && !errors.Is(err, os.ErrNotExist) {
+			slog.Warn("pruning snoozes", "err", err)
+		}
+	}
+
+	if len(remoteHosts) > 0 {
+		// remote repositories cannot be visited from here
+		records, err := hostRecords(remoteHosts, args)
+		if err != nil {
+			return err
+		}
+		return writeReport(records, outputFormat)
+	}
+
+	// the run log sums up the scan of this machine
+	if runLog != "" {
+		defer logRun(runLog, scanPath)
+	}
+	if summaryLogger != nil {
+		defer summarizeRun(scanPath)
+	}
+
+	if outputFormat != "text" || outputFile != "" || copyReport || reportTemplate != "" {
+		if !slices.Contains(OutputFormats, outputFormat) {
+			return fmt.Errorf("unknown format %q, use one of %v", outputFormat, OutputFormats)
+		}
+		records, err := repoRecords(scanPath)
+		if err != nil {
+			return err
+		}
+		if err := writeReport(records, outputFormat); err != nil {
+			return err
+		}
+		// stdout is the report's
+		return errors.Join(reportViolations(os.Stderr), failedErrorsError())
+	}
+
+	// the words of --accessible need no legend
+	if !accessibleOutput {
+		printLegend()
+	}
+
+	projectsToVisit, err := flaggedProjects(scanPath, true)
+	if err != nil {
+		return err
+	}
+
+	if err := warnExpiringSnoozes(scanPath, settings); err != nil {
+		return err
+	}
+	if err := reportMovedRepos(scanPath); err != nil {
+		return err
+	}
+	violationsErr := errors.Join(reportViolations(os.Stdout), failedErrorsError())
+
+	if noVisit {
+		return violationsErr
+	}
+	if err := visit(projectsToVisit, scanPath, settings); err != nil {
+		return err
+	}
+	return violationsErr
+}
+
+// printLegend explains the marks of the status lines
+func printLegend() {
+	if asciiOutput {
+		fmt.Println(tr("Legend:"))
+	} else {
+		fmt.Println(tr("Emoji Legend:"))
+	}
+	fmt.Printf("  %s: %s\n", dirtyMark, tr("Dirty working directory"))
+	fmt.Printf("  %s: %s\n", stashMark, tr("Stashed changes"))
+	fmt.Printf("  %s: %s\n", notUpstreamedMark, tr("Not upstreamed"))
+	fmt.Printf("  %s: %s\n", divergedMark, tr("Diverged from its upstream, rebase or merge needed"))
+	if pullRequests {
+		fmt.Printf("  %s: %s\n", inReviewMark, tr("In review"))
+	}
+	if ciStatus {
+		fmt.Printf("  CI %s/%s/%s: %s\n", ciMarks[gori.CIPassed], ciMarks[gori.CIFailed], ciMarks[gori.CIPending], tr("CI of HEAD passed, failed or is still running"))
+	}
+	if detectOrphans {
+		fmt.Printf("  %s: %s\n", orphanedMark, tr("Orphaned clone, its origin was deleted or archived"))
+	}
+	if checkTags {
+		fmt.Printf("  %s: %s\n", divergedTagsMark, tr("Tags pointing at another commit than on origin"))
+	}
+	if showSnoozed {
+		fmt.Printf("  %s: %s\n", snoozedMark, tr("Snoozed"))
+	}
+	fmt.Println("") // Add a blank line for spacing
+}
+
+// warnExpiringSnoozes points out the snoozes expiring soon, so they can be
+// extended or resolved before the repository reappears unexpectedly
+func warnExpiringSnoozes(scanPath string, settings *gori.Settings) error {
+	window := cmp.Or(expiryWindow, settings.SnoozeExpiryWarning, "3d")
+	if window == "0" {
+		return nil
+	}
+	duration, err := gori.ParseSnoozeDuration(window)
+	if err != nil {
+		return fmt.Errorf("invalid expiry window: %w", err)
+	}
+
+	ignoreConfig, err := gori.LoadMergedIgnoreConfig(scanPath)
+	if err != nil {
+		// already reported while scanning
+		return nil
+	}
+
+	expiring := gori.ExpiringSnoozes(ignoreConfig, duration)
+	if len(expiring) == 0 {
+		return nil
+	}
+	fmt.Println("\n" + tr("Snoozes expiring soon:"))
+	for _, entry := range expiring {
+		fmt.Printf(tr("  %s: %s snooze expires in %s\n"), entry.Repo, entry.Check, gori.FormatDuration(time.Until(entry.Until)))
+	}
+	return nil
+}
+
+// flaggedProjects scans the repositories under scanPath and returns the ones
+// failing a check, in the requested sort order. With display set, every
+// project is shown as soon as its place in the order is known.
+func flaggedProjects(scanPath string, display bool) ([]gori.ProjectStatus, error) {
+	if !slices.Contains(gori.SortOrders, sortOrder) {
+		return nil, fmt.Errorf("unknown sort order %q, use one of %v", sortOrder, gori.SortOrders)
+	}
+	if !slices.Contains(displayStyles, displayStyle) {
+		return nil, fmt.Errorf("unknown display style %q, use one of %v", displayStyle, displayStyles)
+	}
+	if !slices.Contains(groupings, groupBy) {
+		return nil, fmt.Errorf("unknown grouping %q, use one of %v", groupBy, groupings)
+	}
+	displayRoot = scanPath
+	// results stream in by name, other orders, the counts of --accessible and
+	// groups need all of them first
+	stream := display && sortOrder == "name" && !accessibleOutput && groupBy == "none"
+
+	ignoreConfig, err := gori.LoadMergedIgnoreConfig(scanPath)
+	if err != nil {
+		// Log but continue without the ignore file
+		slog.Warn("loading ignore config", "err", err)
+	}
+
+	repoPaths, err := discoverRepos(scanPath)
+	if err != nil {
+		return nil, err
+	}
+	acks, err := readAcks()
+	if err != nil {
+		slog.Warn("loading acknowledgements", "err", err)
+	}
+
+	// handle worker results
+	var projects []gori.ProjectStatus
+	problems := repoProblems{}
+	ackedProjects := 0
+	scanProjects(repoPaths, scanPath, ignoreConfig, func(result repoResult) {
+		if result.err != nil {
+			problems.add(result)
+		} else {
+			project := result.status
+			if !project.Clean() && acked(acks, project) {
+				ackedProjects++
+				return
+			}
+			// red CI is shown as well, but there is nothing to visit
+			if !project.Clean() || (showSnoozed && project.Snoozed()) || project.CI == gori.CIFailed {
+				// problems of repositories not shown do not matter
+				problems.add(result)
+				if stream {
+					displayProjectWithChanges(project, showChanges)
+				}
+				projects = append(projects, project)
+			}
+		}
+	})
+
+	if sortOrder != "name" {
+		if err := gori.SortProjects(projects, sortOrder); err != nil {
+			return nil, err
+		}
+	}
+	if display && !stream {
+		if accessibleOutput {
+			announceCounts(projects, len(problems))
+		}
+		if groupBy == "none" {
+			for _, project := range projects {
+				displayProjectWithChanges(project, showChanges)
+			}
+		} else {
+			displayGroups(projects)
+		}
+	}
+
+	if display && ackedProjects > 0 {
+		fmt.Printf(tr("%d acknowledged repository(s) left out until their status changes.\n"), ackedProjects)
+	}
+	// problems follow the results, or stay out of the way when there are none
+	if display {
+		problems.print(os.Stdout)
+	} else {
+		problems.print(os.Stderr)
+	}
+
+	// snoozed projects are only shown, there is nothing to visit
+	return slices.DeleteFunc(projects, gori.ProjectStatus.Clean), nil
+}
+
+// announceCounts tells up front how many repositories need attention and why,
+// so a screen reader user knows what the list holds
+func announceCounts(projects []gori.ProjectStatus, problems int) {
+	attention, dirty, stash, notUpstreamed := countAttention(projects)
+	if attention == 0 {
+		fmt.Println(tr("No repository needs attention."))
+	} else {
+		fmt.Printf(tr("%d repository(s) need attention: %d dirty, %d with stashed changes, %d not upstreamed.\n"), attention, dirty, stash, notUpstreamed)
+	}
+	if problems > 0 {
+		fmt.Printf(tr("%d repository(s) with problems, listed after the results.\n"), problems)
+	}
+}
+
+// countAttention counts the projects needing attention and the checks they
+// fail, the way their status lines show them
+func countAttention(projects []gori.ProjectStatus) (attention, dirty, stash, notUpstreamed int) {
+	for _, project := range projects {
+		if project.Clean() {
+			continue
+		}
+		attention++
+		dirty += count(project.IsDirty)
+		stash += count(project.HasStash)
+		notUpstreamed += count(!project.IsDirty && !project.Upstreamed)
+	}
+	return attention, dirty, stash, notUpstreamed
+}
+
+// displayGroups shows the projects under a header per group with its
+// subtotals, keeping their order within the group
+func displayGroups(projects []gori.ProjectStatus) {
+	groups := map[string][]gori.ProjectStatus{}
+	for _, project := range projects {
+		group := projectGroup(project)
+		groups[group] = append(groups[group], project)
+	}
+	for i, group := range slices.Sorted(maps.Keys(groups)) {
+		if i > 0 {
+			fmt.Println()
+		}
+		attention, dirty, stash, notUpstreamed := countAttention(groups[group])
+		fmt.Printf(tr("%s, %d need attention: %d dirty, %d with stashed changes, %d not upstreamed\n"), group, attention, dirty, stash, notUpstreamed)
+		for _, project := range groups[group] {
+			fmt.Println("  " + projectStatusLine(project))
+			if project.IsDirty && showChanges {
+				fmt.Println(project.StatusString)
+			}
+			printExplanation(project, "    ")
+		}
+	}
+}
+
+// projectGroup returns the group of the project for --group-by: the host, or
+// the host and organization, of its origin
+func projectGroup(project gori.ProjectStatus) string {
+	return remoteGroup(project.RemoteURL, groupBy)
+}
+
+// remoteGroup returns the host, or for org the host and organization, of the
+// remote URL
+func remoteGroup(remoteURL string, by string) string {
+	if remoteURL == "" {
+		return tr("(no remote)")
+	}
+	segments := strings.Split(strings.TrimPrefix(gori.NormalizeRemoteURL(remoteURL), "/"), "/")
+	if by == "org" && len(segments) > 2 {
+		return segments[0] + "/" + segments[1]
+	}
+	return segments[0]
+}
+
+// repoResult is the outcome of checking a single repository. Problems did not
+// keep the repository from being checked, but may make the status inaccurate.
+type repoResult struct {
+	path     string
+	status   gori.ProjectStatus
+	err      error
+	problems []string
+	// violations are the policies the repository violates
+	violations []gori.Policy
+	// failedErrors are the checks of severity error in the scan root's profile
+	// the repository fails
+	failedErrors []string
+}
+
+// repoProblems are the errors and problems of the results, by repository
+type repoProblems map[string][]string
+
+// add records the error and problems of the result. Directories which are not
+// a git repository are not a problem.
+func (p repoProblems) add(result repoResult) {
+	if result.err != nil && !errors.Is(result.err, git.ErrRepositoryNotExists) {
+		p[result.path] = append(p[result.path], result.err.Error())
+	}
+	p[result.path] = append(p[result.path], result.problems...)
+	if len(p[result.path]) == 0 {
+		delete(p, result.path)
+	}
+}
+
+// print lists the problems in a section of their own, after the results
+func (p repoProblems) print(w io.Writer) {
+	if len(p) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "\n"+tr("Problems:"))
+	for _, path := range slices.Sorted(maps.Keys(p)) {
+		for _, problem := range p[path] {
+			fmt.Fprintf(w, "  %s: %s\n", displayName(path), problem)
+		}
+	}
+}
+
+// discoverRepos lists the directories directly under scanPath, each of which
+// is a candidate repository. Dot-directories are left out unless --hidden is
+// given, exclude_dirs always. A recent enough earlier discovery is reused.
+func discoverRepos(scanPath string) ([]string, error) {
+	if repoPaths, ok := cachedDiscovery(scanPath, loadSettings()); ok {
+		return repoPaths, nil
+	}
+
+	files, err := os.ReadDir(scanPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading directory %s: %w", scanPath, err)
+	}
+
+	excluded := loadSettings().DirExcluder(scanPath)
+	var repoPaths []string
+	for _, file := range files {
+		repoPath := filepath.Join(scanPath, file.Name())
+		// left out before the guards, which could take long on a huge tree
+		if excluded(repoPath) {
+			slog.Info("excluded directory", "dir", repoPath)
+			continue
+		}
+		if file.IsDir() && (includeHidden || !strings.HasPrefix(file.Name(), ".")) {
+			repoPaths = append(repoPaths, repoPath)
+		}
+	}
+	slices.Sort(repoPaths)
+	repoPaths = guardDiscovery(repoPaths, scanPath, loadSettings())
+	cacheDiscovery(scanPath, repoPaths, loadSettings())
+	return repoPaths, nil
+}
+
+// scanProjects checks the repositories concurrently and hands every result to
+// handle, in the order of repoPaths. The ones in aloneRepos are checked while
+// nothing else is, and their memory is handed back to the system right after.
+func scanProjects(repoPaths []string, scanPath string, ignoreConfig *gori.IgnoreConfig, handle func(repoResult)) {
+	start := time.Now()
+	defer func() { thisRun.Seconds += time.Since(start).Seconds() }()
+
+	var mu sync.Mutex
+	cond := sync.NewCond(&mu)
+	results := make(map[string]repoResult)
+	done := make(map[string]bool)
+
+	sem := make(chan struct{}, concurrency)
+
+	// one thread that feeds concurrent workers
+	go func() {
+		for _, path := range repoPaths {
+			// taking every slot waits for the other checks to finish and
+			// keeps new ones from starting
+			slots := 1
+			if aloneRepos[path] {
+				slots = concurrency
+			}
+			for range slots {
+				sem <- struct{}{}
+			}
+			go func(repoPath string) {
+				defer func() {
+					mu.Lock()
+					done[repoPath] = true
+					mu.Unlock()
+					cond.Broadcast()
+				}()
+				result := checkRepoWithin(repoTimeout, func() repoResult {
+					// the slots are only free once the check is, even if
+					// it was given up on, so stuck checks do not pile up
+					defer func() {
+						if aloneRepos[repoPath] {
+							rtdebug.FreeOSMemory()
+						}
+						for range slots {
+							<-sem
+						}
+					}()
+					return checkRepo(repoPath, scanPath, ignoreConfig)
+				})
+
+				mu.Lock()
+				results[repoPath] = result
+				mu.Unlock()
+			}(path)
+		}
+	}()
+
+	for _, repoPath := range repoPaths {
+		mu.Lock()
+		for !done[repoPath] {
+			cond.Wait()
+		}
+		result, ok := results[repoPath]
+		mu.Unlock()
+
+		if !ok {
+			result.err = errors.New("no result")
+		}
+		result.path = repoPath
+		thisRun.tally(result)
+		for _, policy := range result.violations {
+			thisRunViolations = append(thisRunViolations, policyViolation{path: repoPath, policy: policy})
+		}
+		thisRunFailedErrors += count(len(result.failedErrors) > 0)
+		if result.err == nil {
+			thisRunRemotes[repoPath] = result.status.RemoteURL
+		}
+		handle(result)
+	}
+}
+
+// errTimedOut is the error of a repository which took longer than
+// --repo-timeout to check
+var errTimedOut = errors.New("timed out")
+
+// checkRepoWithin runs check, giving up after the timeout unless it is zero.
+// An abandoned check keeps running in the background, as go-git cannot be
+// interrupted, but no longer holds up the results of the others.
+func checkRepoWithin(timeout time.Duration, check func() repoResult) repoResult {
+	if timeout <= 0 {
+		return check()
+	}
+
+	resultC := make(chan repoResult, 1)
+	go func() { resultC <- check() }()
+	select {
+	case result := <-resultC:
+		return result
+	case <-time.After(timeout):
+		return repoResult{err: fmt.Errorf("%w after %s", errTimedOut, timeout)}
+	}
+}
+
+// checkRepo runs every check on a single repository, with the backend of its
+// version control system
+func checkRepo(repoPath string, scanPath string, ignoreConfig *gori.IgnoreConfig) repoResult {
+	start := time.Now()
+	backend := backendFor(repoPath)
+	dirty, changes, err := backend.Status(repoPath)
+	if err != nil {
+		return repoResult{err: err}
+	}
+	upstreamed, problems, err := backend.UpstreamState(repoPath)
+	if err != nil {
+		return repoResult{err: err}
+	}
+	stashes, err := backend.Stashes(repoPath)
+	if err != nil {
+		return repoResult{err: err}
+	}
+
+	project := gori.NewProject(repoPath, dirty, stashes > 0, upstreamed)
+	project.Stashes = stashes
+	project.LastCommit, project.RemoteURL = backend.Details(repoPath)
+
+	profile := loadSettings().ProfileFor(scanPath)
+	checks, err := gori.EffectiveChecks(repoPath, project.RemoteURL, ignoreConfig, scanPath)
+	if err != nil {
+		problems = append(problems, err.Error())
+	}
+	if profile != nil {
+		checks = profile.Checks.Merge(checks)
+	}
+	project.ApplyChecks(checks)
+	if project.IsDirty && dirtyWithinGrace(repoPath, changes) {
+		project.IsDirty = false
+	}
+	if diverging, ok := backend.(divergingBackend); ok && !project.Upstreamed {
+		if upstream, ahead, behind := diverging.Divergence(repoPath); ahead > 0 && behind > 0 {
+			project.Diverged = &gori.Divergence{Upstream: upstream, Ahead: ahead, Behind: behind}
+		}
+	}
+	if hosted, ok := backend.(hostedBackend); ok {
+		problems = append(problems, hosted.LookUpHosting(repoPath, upstreamed, &project)...)
+	}
+	// after the hosting, as a merged pull request upstreams the branch
+	violations := violatedPolicies(project, scanPath)
+
+	if !project.Clean() {
+		// Apply snooze logic
+		gori.ApplySnooze(repoPath, &project, ignoreConfig, scanPath)
+
+		// kept for the (s)tatus action as well, --stat only decides whether
+		// they are shown with the results
+		project.StatusString = changes
+		if explain {
+			project.Explanation = explainProject(backend, project, changes)
+		}
+	}
+
+	slog.Info("checked repository", "repo", repoPath, "dirty", project.IsDirty, "stash", project.HasStash, "upstreamed", project.Upstreamed, "took", time.Since(start).Round(time.Millisecond), "vcs", backend.Name())
+	return repoResult{status: project, problems: problems, violations: violations, failedErrors: profile.FailedErrors(project)}
+}
+
+// selectRepos scans the repositories under scanPath and returns the paths of
+// the flagged ones, narrowed down to those failing one of the only checks.
+// With all set every repository is returned.
+func selectRepos(scanPath string, all bool, only []string) ([]string, error) {
+	for _, check := range only {
+		if !slices.Contains(gori.ValidChecks, check) {
+			return nil, fmt.Errorf("unknown check %q, use one of %v", check, gori.ValidChecks)
+		}
+	}
+
+	ignoreConfig, err := gori.LoadMergedIgnoreConfig(scanPath)
+	if err != nil {
+		slog.Warn("loading ignore config", "err", err)
+	}
+
+	candidates, err := discoverRepos(scanPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var repoPaths []string
+	problems := repoProblems{}
+	scanProjects(candidates, scanPath, ignoreConfig, func(result repoResult) {
+		if result.err != nil {
+			problems.add(result)
+			return
+		}
+		project := result.status
+		if all || (!project.Clean() && failsAnyCheck(project, only)) {
+			problems.add(result)
+			repoPaths = append(repoPaths, result.path)
+		}
+	})
+	problems.print(os.Stderr)
+	return repoPaths, nil
+}
+
+// failsAnyCheck reports whether the project fails one of the checks, no checks
+// meaning any check
+func failsAnyCheck(project gori.ProjectStatus, checks []string) bool {
+	if len(checks) == 0 || slices.Contains(checks, "all") {
+		return !project.Clean()
+	}
+	return (slices.Contains(checks, "dirty") && project.IsDirty) ||
+		(slices.Contains(checks, "stash") && project.HasStash) ||
+		(slices.Contains(checks, "upstream") && !project.Upstreamed)
+}
+
+// discoverGitRepos lists the git repositories directly under scanPath
+func discoverGitRepos(scanPath string) ([]string, error) {
+	candidates, err := discoverRepos(scanPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var repoPaths []string
+	for _, candidate := range candidates {
+		if _, err := gori.OpenRepo(candidate); err == nil {
+			repoPaths = append(repoPaths, candidate)
+		}
+	}
+	return repoPaths, nil
+}
+
+// displayProjectStatus outputs the status of a repository with appropriate emojis
+func displayProjectStatus(project gori.ProjectStatus) {
+	displayProjectWithChanges(project, showChanges)
+}
+
+// displayProjectWithChanges outputs project status and optionally changes
+func displayProjectWithChanges(project gori.ProjectStatus, showChanges bool) {
+	fmt.Println(projectStatusLine(project))
+
+	if project.IsDirty && showChanges {
+		fmt.Printf("%s\n", project.StatusString)
+	}
+	printExplanation(project, "  ")
+}
+
+// projectStatusLine renders the project's name followed by the emojis of the
+// checks it fails
+func projectStatusLine(project gori.ProjectStatus) string {
+	// the marks of the checks go together, the details following them stand
+	// apart
+	var checks, details []string
+	if project.IsDirty {
+		checks = append(checks, dirtyMark.String())
+	}
+
+	if project.HasStash {
+		checks = append(checks, stashText(project.Stashes))
+	}
+
+	if !project.IsDirty && !project.Upstreamed {
+		if project.Diverged != nil {
+			checks = append(checks, divergedMark.String())
+		} else if project.PullRequest != nil && !project.PullRequest.Merged {
+			checks = append(checks, inReviewMark.String()+" "+project.PullRequest.String())
+		} else {
+			checks = append(checks, notUpstreamedMark.String())
+		}
+	}
+
+	if project.Orphaned != "" {
+		details = append(details, orphanedMark.String()+" origin "+project.Orphaned)
+	}
+
+	if len(project.DivergedTags) > 0 {
+		var names []string
+		for _, tag := range project.DivergedTags {
+			names = append(names, tag.Name)
+		}
+		details = append(details, divergedTagsMark.String()+" "+strings.Join(names, ", "))
+	}
+
+	if mark, ok := ciMarks[project.CI]; ok {
+		details = append(details, "CI "+mark.String())
+	}
+
+	if showSnoozed && project.Snoozed() {
+		details = append(details, dim(snoozedMark.String()+" until "+project.SnoozedUntil.Format(time.DateTime)))
+	}
+
+	if showAge && !project.LastCommit.IsZero() {
+		details = append(details, fmt.Sprintf(tr("(last commit %s ago)"), gori.FormatDuration(time.Since(project.LastCommit))))
+	}
+
+	return hyperlink(displayName(project.Path), project) + ": " + joinMarks(checks, details)
+}
+
+// displayName shows the repository as chosen by --display: its directory
+// name, its path relative to the scan root or its absolute path
+func displayName(repoPath string) string {
+	switch displayStyle {
+	case "relative":
+		return gori.NewPathResolver(displayRoot).Rel(repoPath)
+	case "full":
+		if abs, err := filepath.Abs(repoPath); err == nil {
+			return abs
+		}
+	}
+	return filepath.Base(repoPath)
+}
+
+// stashText is the stash mark, followed by the number of stashes when there
+// are more than one
+func stashText(stashes int) string {
+	switch {
+	case stashes < 2:
+		return stashMark.String()
+	case accessibleOutput:
+		return fmt.Sprintf(tr("%d stashes"), stashes)
+	case asciiOutput:
+		return fmt.Sprintf("%sx%d", stashMark, stashes)
+	default:
+		return fmt.Sprintf("%s×%d", stashMark, stashes)
+	}
+}
+
+// joinMarks puts the marks together, separated by commas for --accessible so
+// screen readers pause between them
+func joinMarks(checks, details []string) string {
+	if accessibleOutput {
+		return strings.Join(slices.Concat(checks, details), ", ")
+	}
+	if glued := strings.Join(checks, ""); glued != "" {
+		details = slices.Insert(details, 0, glued)
+	}
+	return strings.Join(details, " ")
+}
+
+// dim renders text faint when stdout is a terminal
+func dim(text string) string {
+	if accessibleOutput || !readline.IsTerminal(int(os.Stdout.Fd())) {
+		return text
+	}
+	return "\x1b[2m" + text + "\x1b[0m"
+}
@@ -0,0 +1,57 @@
+//go:build !windows
+
+package main
+
+import (
+	"context"
+	"log/slog"
+	"log/syslog"
+	"slices"
+)
+
+// openSyslog connects to the local syslog daemon, or to journald through its
+// syslog socket. The first handler logs from level on, the second one from
+// info on, for the run summaries.
+func openSyslog(level slog.Level) (slog.Handler, slog.Handler, error) {
+	w, err := syslog.New(syslog.LOG_USER|syslog.LOG_INFO, "gori")
+	if err != nil {
+		return nil, nil, err
+	}
+	return &syslogHandler{w: w, level: level}, &syslogHandler{w: w, level: slog.LevelInfo}, nil
+}
+
+// syslogHandler writes records like plainHandler, with the level as the
+// syslog severity instead of a prefix
+type syslogHandler struct {
+	w     *syslog.Writer
+	level slog.Level
+	attrs []slog.Attr
+}
+
+func (h *syslogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+func (h *syslogHandler) Handle(_ context.Context, r slog.Record) error {
+	line := formatRecord(h.attrs, r)
+	switch {
+	case r.Level >= slog.LevelError:
+		return h.w.Err(line)
+	case r.Level >= slog.LevelWarn:
+		return h.w.Warning(line)
+	case r.Level >= slog.LevelInfo:
+		return h.w.Info(line)
+	default:
+		return h.w.Debug(line)
+	}
+}
+
+func (h *syslogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.attrs = append(slices.Clone(h.attrs), attrs...)
+	return &clone
+}
+
+func (h *syslogHandler) WithGroup(name string) slog.Handler {
+	return h
+}
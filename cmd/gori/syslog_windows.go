@@ -0,0 +1,12 @@
+//go:build windows
+
+package main
+
+import (
+	"errors"
+	"log/slog"
+)
+
+func openSyslog(level slog.Level) (slog.Handler, slog.Handler, error) {
+	return nil, nil, errors.New("syslog is not available on Windows, log to stderr instead")
+}
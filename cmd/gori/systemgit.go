@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	gitconfig "github.com/go-git/go-git/v5/plumbing/format/config"
+
+	"github.com/hansbogert/gori"
+)
+
+// systemGitBackend checks git repositories with the git command instead of
+// go-git. It takes partial clones, cloned with e.g. --filter=blob:none, whose
+// missing objects go-git cannot fetch from the promisor remote when it needs
+// them; git fetches them on demand.
+type systemGitBackend struct {
+	gitBackend
+}
+
+func (systemGitBackend) Name() string { return "system git" }
+
+// Discover takes the repositories with a promisor remote
+func (systemGitBackend) Discover(repoPath string) bool {
+	return isPartialClone(repoPath)
+}
+
+// isPartialClone reports whether the repository's config has a promisor
+// remote, the way git marks partial clones
+func isPartialClone(repoPath string) bool {
+	file, err := os.Open(filepath.Join(gitCommonDir(repoPath), "config"))
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+	cfg := gitconfig.New()
+	if err := gitconfig.NewDecoder(file).Decode(cfg); err != nil {
+		return false
+	}
+	if cfg.Section("extensions").Option("partialClone") != "" {
+		return true
+	}
+	for _, remote := range cfg.Section("remote").Subsections {
+		if remote.Option("promisor") == "true" {
+			return true
+		}
+	}
+	return false
+}
+
+// gitCommonDir returns the git directory holding the repository's config. A
+// .git file, as in a linked worktree or a submodule, points elsewhere; git
+// resolves it, as only it knows every way it can.
+func gitCommonDir(repoPath string) string {
+	dotGit := filepath.Join(repoPath, git.GitDirName)
+	if info, err := os.Stat(dotGit); err != nil || info.IsDir() {
+		return dotGit
+	}
+	out, err := gitOutput(repoPath, "rev-parse", "--path-format=absolute", "--git-common-dir")
+	if err != nil {
+		return dotGit
+	}
+	return strings.TrimSpace(out)
+}
+
+func (systemGitBackend) Status(repoPath string) (bool, string, error) {
+	out, err := gitOutput(repoPath, "status", "--porcelain=v1", "--untracked-files=all")
+	if err != nil {
+		return false, "", fmt.Errorf("getting repo status: %w", err)
+	}
+	var changes []string
+	for _, line := range strings.Split(strings.TrimRight(out, "\n"), "\n") {
+		// an uncommitted policy file should not make the repo dirty
+		if line == "" || line == "?? "+gori.RepoConfigFile {
+			continue
+		}
+		changes = append(changes, line)
+	}
+	if len(changes) == 0 {
+		return false, "", nil
+	}
+	return true, strings.Join(changes, "\n") + "\n", nil
+}
+
+// UpstreamState reads the references with go-git, which has them all, and
+// leaves comparing the commits to git
+func (systemGitBackend) UpstreamState(repoPath string) (bool, []string, error) {
+	repo, err := gori.OpenRepo(repoPath)
+	if err != nil {
+		return false, nil, fmt.Errorf("opening repo: %w", err)
+	}
+	upstreamed, problems := checkUpstream(repo, repoPath, func(repo *git.Repository, localBranchName, remoteBranchName string) (bool, error) {
+		return isBranchUpstreamedWithGit(repo, repoPath, localBranchName, remoteBranchName)
+	})
+	return upstreamed, problems, nil
+}
+
+// isBranchUpstreamedWithGit is isBranchUpstreamed asking git merge-base
+func isBranchUpstreamedWithGit(repo *git.Repository, repoPath, localBranchName, remoteBranchName string) (bool, error) {
+	local := plumbing.NewBranchReferenceName(localBranchName)
+	if _, err := repo.Reference(local, true); err != nil {
+		return false, fmt.Errorf("could not get local branch: %w", err)
+	}
+	remote := plumbing.NewRemoteReferenceName("origin", remoteBranchName)
+	if _, err := repo.Reference(remote, true); err != nil {
+		return false, err
+	}
+
+	_, err := gitOutput(repoPath, "merge-base", "--is-ancestor", local.String(), remote.String())
+	if exitErr := (*exec.ExitError)(nil); errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// gitOutput runs a git command in the repository and returns its output
+func gitOutput(repoPath string, args ...string) (string, error) {
+	return gitOutputEnv(repoPath, nil, args...)
+}
+
+// gitOutputEnv is gitOutput with the variables added to the environment of
+// git, like GIT_INDEX_FILE
+func gitOutputEnv(repoPath string, env []string, args ...string) (string, error) {
+	return gitOutputInput(repoPath, env, "", args...)
+}
+
+// gitOutputInput is gitOutputEnv feeding the input to git, like a patch to
+// git patch-id
+func gitOutputInput(repoPath string, env []string, input string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", repoPath}, args...)...)
+	if env != nil {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	if input != "" {
+		cmd.Stdin = strings.NewReader(input)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if stderr.Len() > 0 {
+			return "", fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+		}
+		return "", err
+	}
+	return string(out), nil
+}
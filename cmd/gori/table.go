@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// tableWriter takes rows of tab separated cells, the first row being the
+// header, and writes them out on Flush
+type tableWriter interface {
+	io.Writer
+	Flush() error
+}
+
+// newTable lays out the rows in columns, or for --accessible writes each row
+// as a line of its cells named after their header, which screen readers can
+// follow
+func newTable(w io.Writer) tableWriter {
+	if accessibleOutput {
+		return &listWriter{out: w}
+	}
+	return tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+}
+
+// listWriter writes rows as "header cell, header cell", leaving out empty
+// cells
+type listWriter struct {
+	out io.Writer
+	buf bytes.Buffer
+}
+
+func (l *listWriter) Write(p []byte) (int, error) {
+	return l.buf.Write(p)
+}
+
+func (l *listWriter) Flush() error {
+	rows := strings.Split(strings.TrimSuffix(l.buf.String(), "\n"), "\n")
+	l.buf.Reset()
+	header := strings.Split(rows[0], "\t")
+	for _, row := range rows[1:] {
+		var cells []string
+		for i, cell := range strings.Split(row, "\t") {
+			if cell != "" && i < len(header) {
+				cells = append(cells, strings.ToLower(header[i])+" "+cell)
+			}
+		}
+		if _, err := fmt.Fprintln(l.out, strings.Join(cells, ", ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+
+	"github.com/hansbogert/gori"
+)
+
+var checkTags bool
+
+// detectDivergedTags compares the tags of a project with those of the same
+// name on origin, listing the remote's references. Annotated tags count by the
+// commit they point at, so recreating one on the same commit is fine.
+func detectDivergedTags(repo *git.Repository, project *gori.ProjectStatus) error {
+	remote, err := repo.Remote("origin")
+	if err != nil {
+		return nil
+	}
+	refs, err := remote.List(&git.ListOptions{PeelingOption: git.AppendPeeled})
+	if errors.Is(err, transport.ErrEmptyRemoteRepository) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("listing tags of origin: %w", err)
+	}
+
+	remoteTags := map[string]plumbing.Hash{}
+	peeled := map[string]plumbing.Hash{}
+	for _, ref := range refs {
+		if !ref.Name().IsTag() {
+			continue
+		}
+		if name, ok := strings.CutSuffix(ref.Name().Short(), "^{}"); ok {
+			peeled[name] = ref.Hash()
+		} else {
+			remoteTags[name] = ref.Hash()
+		}
+	}
+	for name, hash := range peeled {
+		remoteTags[name] = hash
+	}
+
+	tags, err := repo.Tags()
+	if err != nil {
+		return fmt.Errorf("listing tags: %w", err)
+	}
+	var diverged []gori.DivergedTag
+	_ = tags.ForEach(func(ref *plumbing.Reference) error {
+		name := ref.Name().Short()
+		remoteHash, ok := remoteTags[name]
+		if !ok {
+			return nil
+		}
+		local := ref.Hash()
+		if tag, err := repo.TagObject(local); err == nil {
+			local = tag.Target
+		}
+		if local != remoteHash {
+			diverged = append(diverged, gori.DivergedTag{Name: name, Local: local.String(), Remote: remoteHash.String()})
+		}
+		return nil
+	})
+	slices.SortFunc(diverged, func(a, b gori.DivergedTag) int { return strings.Compare(a.Name, b.Name) })
+	project.DivergedTags = diverged
+	return nil
+}
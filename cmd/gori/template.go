@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"text/template"
+	"time"
+
+	"github.com/hansbogert/gori"
+)
+
+var reportTemplate string
+
+// templateData is what a report template is executed with
+type templateData struct {
+	// Root is the scan root
+	Root string
+	// Now is when the repositories were checked
+	Now   time.Time
+	Repos []repoRecord
+}
+
+// templateGroup is a group of repositories, as returned by groupBy
+type templateGroup struct {
+	Name  string
+	Repos []repoRecord
+}
+
+// templateFuncs are the helpers report templates can use besides the
+// builtin ones
+var templateFuncs = template.FuncMap{
+	// ago tells how long ago the time was, e.g. 3d, empty for the zero time
+	"ago": func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return gori.FormatDuration(time.Since(t))
+	},
+	// pluralize puts the count before the singular or plural word
+	"pluralize": func(n int, singular string, plural string) string {
+		if n == 1 {
+			return fmt.Sprintf("%d %s", n, singular)
+		}
+		return fmt.Sprintf("%d %s", n, plural)
+	},
+	// groupBy groups the repositories by the host or org of their origin, or
+	// by the machine they are on, sorted by name
+	"groupBy": func(by string, repos []repoRecord) ([]templateGroup, error) {
+		groups := map[string][]repoRecord{}
+		for _, repo := range repos {
+			var name string
+			switch by {
+			case "host", "org":
+				name = remoteGroup(repo.Remote, by)
+			case "machine":
+				name = repo.Host
+			default:
+				return nil, fmt.Errorf("unknown grouping %q, use host, org or machine", by)
+			}
+			groups[name] = append(groups[name], repo)
+		}
+		var sorted []templateGroup
+		for _, name := range slices.Sorted(maps.Keys(groups)) {
+			sorted = append(sorted, templateGroup{Name: name, Repos: groups[name]})
+		}
+		return sorted, nil
+	},
+	// base is the last element of the path, the repository's directory name
+	"base": filepath.Base,
+}
+
+// renderTemplate executes the template file with the records
+func renderTemplate(w io.Writer, templateFile string, records []repoRecord) error {
+	content, err := os.ReadFile(templateFile)
+	if err != nil {
+		return fmt.Errorf("reading template: %w", err)
+	}
+	tmpl, err := template.New(filepath.Base(templateFile)).Funcs(templateFuncs).Parse(string(content))
+	if err != nil {
+		return fmt.Errorf("parsing template: %w", err)
+	}
+	return tmpl.Execute(w, templateData{Root: displayRoot, Now: time.Now(), Repos: records})
+}
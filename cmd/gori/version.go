@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	buildinfo "runtime/debug"
+
+	"github.com/spf13/cobra"
+
+	"github.com/hansbogert/gori"
+)
+
+// goGitModule is the module path of go-git, whose version decides which
+// repositories gori can read
+const goGitModule = "github.com/go-git/go-git/v5"
+
+// newVersionCmd builds the command reporting what this gori was built from
+func newVersionCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "version",
+		Short: "Print the version, commit and dependencies of gori and the config versions it understands",
+		RunE:  runVersion,
+		Args:  cobra.NoArgs,
+	}
+}
+
+func runVersion(cmd *cobra.Command, args []string) error {
+	commit, goGit := "unknown", "unknown"
+	if info, ok := buildinfo.ReadBuildInfo(); ok {
+		commit = buildCommit(info)
+		for _, dep := range info.Deps {
+			if dep.Path == goGitModule {
+				goGit = moduleVersion(dep)
+			}
+		}
+	}
+
+	fmt.Printf("gori %s\n", buildVersion())
+	fmt.Printf("commit: %s\n", commit)
+	fmt.Printf("go: %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	fmt.Printf("go-git: %s\n", goGit)
+	fmt.Printf("config schema version: %d\n", gori.IgnoreConfigVersion)
+	return nil
+}
+
+// buildCommit is the revision gori was built from, marked when the work tree
+// had changes
+func buildCommit(info *buildinfo.BuildInfo) string {
+	commit, modified := "unknown", false
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			commit = setting.Value
+		case "vcs.modified":
+			modified = setting.Value == "true"
+		}
+	}
+	if modified {
+		commit += " (modified)"
+	}
+	return commit
+}
+
+// moduleVersion is the version of a dependency, including what replaced it
+func moduleVersion(dep *buildinfo.Module) string {
+	if dep.Replace == nil {
+		return dep.Version
+	}
+	replacement := dep.Replace.Path
+	if dep.Replace.Version != "" {
+		replacement += " " + dep.Replace.Version
+	}
+	return fmt.Sprintf("%s (replaced by %s)", dep.Version, replacement)
+}
@@ -0,0 +1,649 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/chzyer/readline"
+	"github.com/spf13/cobra"
+
+	"github.com/hansbogert/gori"
+)
+
+var visitOnly []string
+
+func newVisitCmd() *cobra.Command {
+	visitCmd := &cobra.Command{
+		Use:               "visit [path]",
+		Short:             "Interactively visit the repositories failing a check",
+		RunE:              runVisit,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeScanRoot,
+	}
+	addVisitFlags(visitCmd)
+	visitCmd.Flags().BoolVar(&resumeSession, "resume", false, "continue the last visit of the scan root from the project it was at, skipping the ones clean by now")
+	return visitCmd
+}
+
+// addVisitFlags adds the flags controlling which projects are visited and how
+func addVisitFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVarP(&showChanges, "stat", "s", false, "stat the files if the work tree is not clean")
+	cmd.Flags().StringVar(&sortOrder, "sort", "name", fmt.Sprintf("order of listing and visiting projects, one of %v", gori.SortOrders))
+	cmd.Flags().StringVar(&displayStyle, "display", "base", fmt.Sprintf("how repositories are named, one of %v: the directory name, the path relative to the scan root or the absolute path", displayStyles))
+	cmd.Flags().StringSliceVar(&visitOnly, "visit-only", nil, "only visit the given projects, skipping the selection prompt")
+	cmd.Flags().BoolVarP(&dryRun, "dry-run", "n", false, "print the changes snoozing makes to the ignore file, and what (w)ip would do, instead of making them")
+	_ = cmd.RegisterFlagCompletionFunc("sort", completeValues(gori.SortOrders))
+	_ = cmd.RegisterFlagCompletionFunc("display", completeValues(displayStyles))
+	_ = cmd.RegisterFlagCompletionFunc("visit-only", completeList(func(cmd *cobra.Command, args []string) []string {
+		return discoveredNames(scanPathArg(args))
+	}))
+}
+
+func runVisit(cmd *cobra.Command, args []string) error {
+	scanPath := scanPathArg(args)
+	var projectsToVisit []gori.ProjectStatus
+	var err error
+	if resumeSession {
+		projectsToVisit, err = resumedProjects(scanPath)
+	} else {
+		projectsToVisit, err = flaggedProjects(scanPath, false)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(projectsToVisit) == 0 {
+		clearSession(scanPath)
+		fmt.Println(tr("Nothing to visit."))
+		return nil
+	}
+	return visit(projectsToVisit, scanPath, loadSettings())
+}
+
+// visit walks through the projects, narrowed down to --visit-only if given
+func visit(projects []gori.ProjectStatus, scanPath string, settings *gori.Settings) error {
+	if len(visitOnly) > 0 {
+		projects = filterProjects(projects, visitOnly)
+	}
+
+	// Ask if user wants to visit projects
+	if len(projects) > 0 {
+		visitProjects(projects, scanPath, settings)
+	}
+	return nil
+}
+
+// visitProjects interactively walks through each project with issues. Where
+// it got to is saved as the visit session of the scan root until every
+// project was visited.
+func visitProjects(projects []gori.ProjectStatus, scanPath string, settings *gori.Settings) {
+	rl, err := newPrompt()
+	if err != nil {
+		fmt.Printf(tr("Error: could not start prompt: %s\n"), err)
+		return
+	}
+	defer rl.Close()
+	actions := customActions(settings)
+
+	menu := tr("(s)tatus, (p)rint results, (i)gnore, ignore (a)ll, (u)nsnooze, ac(k), (d)one, (w)ip, (n)ext, (e)xecute shell, (o)pen, (g)it-ui")
+	for _, action := range actions {
+		menu += fmt.Sprintf(", (%s) %s", action.Key, action.Label)
+	}
+	menu += tr(", (q)uit: ")
+
+	// a resumed session was selected from already
+	if len(visitOnly) == 0 && !resumeSession && len(projects) > 1 {
+		projects = selectProjects(projects, rl)
+	}
+	summary := &visitSummary{visited: len(projects)}
+	defer summary.print()
+
+	var next *prefetch
+	for i, project := range projects {
+		if next != nil {
+			project = next.project(project)
+			if project.Clean() {
+				fmt.Printf(tr("\nProject %d/%d: %s is clean now.\n"), i+1, len(projects), displayName(project.Path))
+				continue
+			}
+		}
+		saveSession(scanPath, projects, i)
+		next = nil
+		if i+1 < len(projects) {
+			next = prefetchProject(projects[i+1].Path, scanPath)
+		}
+		// the changes found while checking hold until an action may have
+		// changed the working directory
+		stale := false
+		before := project
+
+	project:
+		for {
+			snoozed := ""
+			if project.Snoozed() {
+				snoozed = tr(" (partially snoozed)")
+			}
+			fmt.Printf(tr("\nProject %d/%d: %s%s\n\n"), i+1, len(projects), displayName(project.Path), snoozed)
+			rl.SetPrompt(menu)
+			input, err := rl.Readline()
+			if err != nil {
+				// ctrl-c and ctrl-d quit like (q)uit does
+				fmt.Printf(tr("Left off at %s, gori visit --resume continues from there.\n"), displayName(project.Path))
+				return
+			}
+			input = strings.TrimSpace(strings.ToLower(input))
+			parts := strings.Fields(input)
+			if len(parts) == 0 {
+				continue
+			}
+			command := parts[0]
+
+			switch command {
+			case "s":
+				if stale {
+					if err := refreshChanges(&project); err != nil {
+						fmt.Printf(tr("Error: %s\n"), err)
+						continue
+					}
+					stale = false
+				}
+				page(fmt.Sprintf("\n%s\n", project.StatusString), settings)
+			case "p":
+				for _, proj := range projects {
+					displayProjectWithChanges(proj, showChanges)
+				}
+			case "i":
+				durationStr, check, ok := snoozeArgs(parts, settings)
+				if !ok {
+					continue
+				}
+				gori.SnoozeCheck(project, durationStr, check, scanPath, dryRun)
+			case "a":
+				durationStr, check, ok := snoozeArgs(parts, settings)
+				if !ok {
+					continue
+				}
+				var paths []string
+				for _, p := range projects {
+					paths = append(paths, p.Path)
+				}
+				if err := gori.AddSnoozes(scanPath, paths, durationStr, check, "", dryRun); err != nil {
+					fmt.Printf(tr("Error: %s\n"), err)
+					continue
+				}
+				fmt.Printf(tr("Snoozed %s of %d project(s) for %s.\n"), check, len(paths), durationStr)
+				clearSession(scanPath)
+				return
+			case "u":
+				// u [duration] [check], without a duration the snooze is removed
+				durationStr := ""
+				check := "all"
+				args := parts[1:]
+				if len(args) > 0 && !slices.Contains(gori.ValidChecks, args[0]) {
+					durationStr = args[0]
+					args = args[1:]
+				}
+				if len(args) > 0 {
+					check = args[0]
+				}
+				gori.UnsnoozeCheck(project, durationStr, check, scanPath, dryRun)
+			case "k":
+				if stale {
+					// acknowledge what is there now, not what was found
+					ignoreConfig, _ := gori.LoadMergedIgnoreConfig(scanPath)
+					result := checkRepo(project.Path, scanPath, ignoreConfig)
+					if result.err != nil {
+						fmt.Printf(tr("Error: %s\n"), result.err)
+						continue
+					}
+					project, stale = result.status, false
+				}
+				if err := ackProject(project); err != nil {
+					fmt.Printf(tr("Error: %s\n"), err)
+					continue
+				}
+				fmt.Printf(tr("Acknowledged %s, it is left out until its status changes.\n"), displayName(project.Path))
+				break project
+			case "d":
+				// checked right away, whether the work on it was done or not
+				ignoreConfig, _ := gori.LoadMergedIgnoreConfig(scanPath)
+				result := checkRepo(project.Path, scanPath, ignoreConfig)
+				if result.err != nil {
+					fmt.Printf(tr("Error: %s\n"), result.err)
+					continue
+				}
+				project, stale = result.status, false
+				if !project.Clean() {
+					fmt.Printf(tr("Not done yet: %s\n"), projectStatusLine(project))
+					continue
+				}
+				summary.resolved = append(summary.resolved, before)
+				fmt.Printf(tr("%s is clean now.\n"), displayName(project.Path))
+				break project
+			case "w":
+				visitWIP(project.Path, parts, dryRun)
+				stale = true
+			case "n":
+				break project
+			case "e":
+				executeSecureSubshell(project.Path)
+				stale = true
+			case "o":
+				openInEditor(project.Path, settings)
+				stale = true
+			case "g":
+				openGitUI(project.Path, settings)
+				stale = true
+			case "q":
+				fmt.Printf(tr("Left off at %s, gori visit --resume continues from there.\n"), displayName(project.Path))
+				return
+			default:
+				i := slices.IndexFunc(actions, func(a gori.Action) bool { return a.Key == command })
+				if i < 0 {
+					fmt.Println(tr("Invalid command."))
+					continue
+				}
+				action := actions[i]
+				args, err := action.Args(project)
+				if err != nil {
+					fmt.Printf(tr("Error: %s\n"), err)
+					continue
+				}
+				runInProject(project.Path, args, action.Label)
+				stale = true
+			}
+		}
+	}
+	clearSession(scanPath)
+}
+
+// visitSummary is the outcome of visiting projects, shown at the end
+type visitSummary struct {
+	// visited is how many projects there were to visit
+	visited int
+	// resolved are the projects marked (d)one, as they were before
+	resolved []gori.ProjectStatus
+}
+
+// print shows how many projects were resolved and what they looked like
+// before, when any were
+func (s *visitSummary) print() {
+	if len(s.resolved) == 0 {
+		return
+	}
+	fmt.Printf("\n"+tr("Resolved %d of %d project(s), %d left:\n"), len(s.resolved), s.visited, s.visited-len(s.resolved))
+	for _, project := range s.resolved {
+		fmt.Printf(tr("  %s -> clean\n"), projectStatusLine(project))
+	}
+}
+
+// prefetch checks a project again in the background while the user is busy
+// with the one before it, so it is current without a pause once visited
+type prefetch struct {
+	done     chan struct{}
+	result   repoResult
+	scanPath string
+}
+
+// prefetchProject starts checking the project again
+func prefetchProject(projectPath string, scanPath string) *prefetch {
+	p := &prefetch{done: make(chan struct{}), scanPath: scanPath}
+	go func() {
+		defer close(p.done)
+		// errors loading the snoozes were reported while scanning
+		ignoreConfig, _ := gori.LoadMergedIgnoreConfig(scanPath)
+		p.result = checkRepo(projectPath, scanPath, ignoreConfig)
+	}()
+	return p
+}
+
+// project waits for the check and returns its outcome, or the project as
+// scanned when checking it again failed. Snoozes added while it was checked
+// apply as well.
+func (p *prefetch) project(scanned gori.ProjectStatus) gori.ProjectStatus {
+	<-p.done
+	if p.result.err != nil {
+		slog.Warn("checking the project again", "repo", scanned.Path, "err", p.result.err)
+		return scanned
+	}
+	project := p.result.status
+	if ignoreConfig, err := gori.LoadMergedIgnoreConfig(p.scanPath); err == nil {
+		gori.ApplySnooze(project.Path, &project, ignoreConfig, p.scanPath)
+	}
+	return project
+}
+
+// refreshChanges checks the working directory of the project again with its
+// backend, for the (s)tatus action after the project was worked on
+func refreshChanges(project *gori.ProjectStatus) error {
+	_, changes, err := backendFor(project.Path).Status(project.Path)
+	if err != nil {
+		return err
+	}
+	project.StatusString = changes
+	return nil
+}
+
+// snoozeArgs parses "i [duration] [check]", defaulting to the configured
+// duration and check. It tells the user when no duration is known.
+func snoozeArgs(parts []string, settings *gori.Settings) (string, string, bool) {
+	durationStr := settings.SnoozeDuration
+	check := cmp.Or(settings.SnoozeCheck, "all")
+	args := parts[1:]
+	if len(args) > 0 && !slices.Contains(gori.ValidChecks, args[0]) {
+		durationStr = args[0]
+		args = args[1:]
+	}
+	if len(args) > 0 {
+		check = args[0]
+	}
+	if durationStr == "" {
+		fmt.Printf(tr("Usage: %s <duration> [check], or set snooze_duration in gori.cue\n"), parts[0])
+		return "", "", false
+	}
+	return durationStr, check, true
+}
+
+// filterProjects keeps the projects whose directory name is one of names
+func filterProjects(projects []gori.ProjectStatus, names []string) []gori.ProjectStatus {
+	var selected []gori.ProjectStatus
+	for _, project := range projects {
+		if slices.Contains(names, filepath.Base(project.Path)) {
+			selected = append(selected, project)
+		}
+	}
+	return selected
+}
+
+// newPrompt creates the line editor used for interactive input, keeping its
+// history across runs in the cache dir when possible
+func newPrompt() (*readline.Instance, error) {
+	config := &readline.Config{}
+	if cacheDir, err := gori.CacheDir(); err == nil {
+		if err := os.MkdirAll(cacheDir, 0755); err == nil {
+			config.HistoryFile = filepath.Join(cacheDir, "history")
+		}
+	}
+	return readline.NewEx(config)
+}
+
+// selectProjects lets the user pick which of the projects to visit
+func selectProjects(projects []gori.ProjectStatus, rl *readline.Instance) []gori.ProjectStatus {
+	fmt.Println()
+	for i, project := range projects {
+		fmt.Printf("  [%d] %s\n", i+1, displayName(project.Path))
+	}
+
+	fmt.Println()
+	rl.SetPrompt(tr("Select projects to visit (e.g. 1,3-5), empty for all: "))
+	for {
+		input, err := rl.Readline()
+		if err != nil {
+			return projects
+		}
+		indices, err := parseSelection(input, len(projects))
+		if err != nil {
+			fmt.Println(tr("Invalid selection:"), err)
+			continue
+		}
+
+		var selected []gori.ProjectStatus
+		for _, i := range indices {
+			selected = append(selected, projects[i])
+		}
+		return selected
+	}
+}
+
+// parseSelection parses a selection like "1,3-5" into sorted zero-based
+// indices of a list with n items. An empty selection selects everything.
+func parseSelection(input string, n int) ([]int, error) {
+	input = strings.TrimSpace(input)
+	if input == "" || input == "all" {
+		var all []int
+		for i := range n {
+			all = append(all, i)
+		}
+		return all, nil
+	}
+
+	var indices []int
+	for _, part := range strings.FieldsFunc(input, func(r rune) bool { return r == ',' || r == ' ' }) {
+		from, to, isRange := strings.Cut(part, "-")
+		start, err := strconv.Atoi(from)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", from)
+		}
+		end := start
+		if isRange {
+			end, err = strconv.Atoi(to)
+			if err != nil {
+				return nil, fmt.Errorf("%q is not a number", to)
+			}
+		}
+		if start < 1 || end > n || start > end {
+			return nil, fmt.Errorf("%q is not within 1-%d", part, n)
+		}
+		for i := start; i <= end; i++ {
+			if !slices.Contains(indices, i-1) {
+				indices = append(indices, i-1)
+			}
+		}
+	}
+	slices.Sort(indices)
+	return indices, nil
+}
+
+// askYesNo asks a question and reports whether it was answered with yes
+func askYesNo(rl *readline.Instance, question string) bool {
+	rl.SetPrompt(question)
+	answer, err := rl.Readline()
+	if err != nil {
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// subshell returns the shell to start in a project and the directories it
+// must be in to be trusted: $SHELL, or else bash, on Unix, and PowerShell, or
+// else %COMSPEC%, on Windows
+func subshell() (string, []string) {
+	if runtime.GOOS == "windows" {
+		trustedDirs := []string{
+			cmp.Or(os.Getenv("SystemRoot"), `C:\Windows`) + `\`,
+			cmp.Or(os.Getenv("ProgramFiles"), `C:\Program Files`) + `\PowerShell\`,
+		}
+		for _, shell := range []string{"pwsh.exe", "powershell.exe"} {
+			if path, err := exec.LookPath(shell); err == nil && trustedShell(path, trustedDirs) {
+				return path, trustedDirs
+			}
+		}
+		return cmp.Or(os.Getenv("COMSPEC"), "cmd.exe"), trustedDirs
+	}
+
+	shellPath := os.Getenv("SHELL")
+	if shellPath == "" {
+		shellPath = "/bin/bash" // fallback to bash if SHELL is not set
+	}
+	return shellPath, []string{"/bin/", "/usr/bin/", "/sbin/", "/usr/sbin/", "/usr/local/bin/", "/usr/local/sbin/"}
+}
+
+// trustedShell reports whether the shell is in one of the trusted directories,
+// ignoring case on Windows
+func trustedShell(shellPath string, trustedDirs []string) bool {
+	for _, dir := range trustedDirs {
+		if strings.HasPrefix(shellPath, dir) || runtime.GOOS == "windows" && len(shellPath) >= len(dir) && strings.EqualFold(shellPath[:len(dir)], dir) {
+			return true
+		}
+	}
+	return false
+}
+
+func executeSecureSubshell(projectPath string) {
+	shellPath, trustedDirs := subshell()
+
+	// Resolve the absolute path of the shell executable
+	resolvedPath, err := exec.LookPath(shellPath)
+	if err != nil {
+		fmt.Printf(tr("Error: could not find shell executable '%s': %v. Aborting.\n"), shellPath, err)
+		return
+	}
+
+	if !trustedShell(resolvedPath, trustedDirs) {
+		fmt.Printf(tr("Error: SHELL environment variable points to a non-standard location: %s. For security, only shells in %v are allowed. Aborting.\n"), resolvedPath, trustedDirs)
+		return
+	}
+
+	cmd := exec.Command(resolvedPath)
+	cmd.Dir = projectPath
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		fmt.Printf(tr("Error starting subshell: %s\n"), err)
+	}
+}
+
+// builtinKeys are the visit menu keys which custom actions cannot override
+var builtinKeys = []string{"s", "p", "i", "a", "u", "k", "d", "w", "n", "e", "o", "g", "q"}
+
+// customActions returns the configured actions, skipping those whose key
+// clashes with a builtin or an earlier action
+func customActions(settings *gori.Settings) []gori.Action {
+	var actions []gori.Action
+	for _, action := range settings.Actions {
+		action.Key = strings.ToLower(action.Key)
+		if action.Key == "" || slices.Contains(builtinKeys, action.Key) {
+			slog.Warn("ignoring action, its key is not available", "action", action.Label, "key", action.Key)
+			continue
+		}
+		if slices.ContainsFunc(actions, func(a gori.Action) bool { return a.Key == action.Key }) {
+			slog.Warn("ignoring action, its key is already used", "action", action.Label, "key", action.Key)
+			continue
+		}
+		actions = append(actions, action)
+	}
+	return actions
+}
+
+// editorCommand determines the command for opening a project, preferring the
+// configured editor over $VISUAL and $EDITOR. A bare command gets the project
+// directory appended as its argument.
+func editorCommand(settings *gori.Settings) ([]string, error) {
+	editor := settings.Editor
+	if editor == "" {
+		editor = os.Getenv("VISUAL")
+	}
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+
+	args := strings.Fields(editor)
+	if len(args) == 0 {
+		return nil, errors.New("no editor configured, set editor in gori.cue, $VISUAL or $EDITOR")
+	}
+	if len(args) == 1 {
+		args = append(args, ".")
+	}
+	return args, nil
+}
+
+func openInEditor(projectPath string, settings *gori.Settings) {
+	args, err := editorCommand(settings)
+	if err != nil {
+		fmt.Printf(tr("Error: %s\n"), err)
+		return
+	}
+	runInProject(projectPath, args, "editor")
+}
+
+// knownGitUIs are tried in order when no git UI is configured
+var knownGitUIs = []string{"lazygit", "tig", "gitui"}
+
+// gitUICommand determines the git UI to hand off to, preferring the configured
+// one over the first known git UI found on the PATH
+func gitUICommand(settings *gori.Settings) ([]string, error) {
+	if args := strings.Fields(settings.GitUI); len(args) > 0 {
+		return args, nil
+	}
+
+	for _, ui := range knownGitUIs {
+		if _, err := exec.LookPath(ui); err == nil {
+			return []string{ui}, nil
+		}
+	}
+	return nil, fmt.Errorf("no git UI configured and none of %v found, set git_ui in gori.cue", knownGitUIs)
+}
+
+func openGitUI(projectPath string, settings *gori.Settings) {
+	args, err := gitUICommand(settings)
+	if err != nil {
+		fmt.Printf(tr("Error: %s\n"), err)
+		return
+	}
+	runInProject(projectPath, args, "git UI")
+}
+
+// pagerCommand determines the pager for long output, preferring the configured
+// one over $PAGER and less
+func pagerCommand(settings *gori.Settings) []string {
+	return strings.Fields(cmp.Or(settings.Pager, os.Getenv("PAGER"), "less"))
+}
+
+// page prints the text, through the pager when it does not fit on the terminal
+func page(text string, settings *gori.Settings) {
+	fd := int(os.Stdout.Fd())
+	_, height, err := readline.GetSize(fd)
+	if err != nil || !readline.IsTerminal(fd) || strings.Count(text, "\n") < height {
+		fmt.Print(text)
+		return
+	}
+
+	args := pagerCommand(settings)
+	resolvedPath, err := exec.LookPath(args[0])
+	if err != nil {
+		fmt.Printf(tr("Error: could not find pager executable '%s': %v.\n"), args[0], err)
+		fmt.Print(text)
+		return
+	}
+	cmd := exec.Command(resolvedPath, args[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	// like git, let less keep colors and leave the text on the screen
+	if _, ok := os.LookupEnv("LESS"); !ok {
+		cmd.Env = append(os.Environ(), "LESS=FRX")
+	}
+	if err := cmd.Run(); err != nil {
+		fmt.Printf(tr("Error starting pager: %s\n"), err)
+	}
+}
+
+// runInProject runs an interactive program inside the project directory and
+// returns once it exits
+func runInProject(projectPath string, args []string, what string) {
+	resolvedPath, err := exec.LookPath(args[0])
+	if err != nil {
+		fmt.Printf(tr("Error: could not find %s executable '%s': %v. Aborting.\n"), what, args[0], err)
+		return
+	}
+
+	cmd := exec.Command(resolvedPath, args[1:]...)
+	cmd.Dir = projectPath
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		fmt.Printf(tr("Error starting %s: %s\n"), what, err)
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var wipBranch bool
+var wipPush bool
+var wipLabel string
+
+func newWIPCmd() *cobra.Command {
+	wipCmd := &cobra.Command{
+		Use:               "wip [path]",
+		Short:             "Set the uncommitted work of every dirty repository aside, stashed or committed on a WIP branch",
+		RunE:              runWIP,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeScanRoot,
+	}
+	wipCmd.Flags().BoolVarP(&dryRun, "dry-run", "n", false, "only list the repositories with uncommitted work")
+	wipCmd.Flags().BoolVar(&wipBranch, "branch", false, "commit the work as WIP on a new wip/ branch instead of stashing it")
+	wipCmd.Flags().BoolVar(&wipPush, "push", false, "push the WIP branch to origin, with --branch")
+	wipCmd.Flags().StringVarP(&wipLabel, "message", "m", "", "label of the stashes or WIP commits (default gori wip and the time)")
+	return wipCmd
+}
+
+// runWIP secures the uncommitted work of every git repository, snoozed or not,
+// e.g. before reinstalling the machine
+func runWIP(cmd *cobra.Command, args []string) error {
+	scanPath := scanPathArg(args)
+	if wipPush && !wipBranch {
+		return errors.New("--push needs --branch, stashes cannot be pushed")
+	}
+
+	repoPaths, err := discoverGitRepos(scanPath)
+	if err != nil {
+		return err
+	}
+
+	var dirty []string
+	for _, repoPath := range repoPaths {
+		if changes, err := gitOutput(repoPath, "status", "--porcelain"); err == nil && changes != "" {
+			fmt.Printf("%s: %d change(s)\n", filepath.Base(repoPath), strings.Count(changes, "\n"))
+			dirty = append(dirty, repoPath)
+		}
+	}
+	if dryRun || len(dirty) == 0 {
+		return nil
+	}
+
+	label := cmp.Or(wipLabel, "gori wip "+time.Now().Format(time.DateTime))
+	errs := forEachRepo(dirty, func(repoPath string) error {
+		done, err := secureWork(repoPath, label, wipBranch)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s: %s\n", filepath.Base(repoPath), done)
+		return nil
+	})
+
+	if len(errs) > 0 {
+		fmt.Println("\nFailed to set work aside:")
+		for _, repoPath := range slices.Sorted(maps.Keys(errs)) {
+			fmt.Printf("  %s: %s\n", filepath.Base(repoPath), errs[repoPath])
+		}
+		return fmt.Errorf("setting work aside failed in %d of %d repositories", len(errs), len(dirty))
+	}
+	return nil
+}
+
+// secureWork sets the uncommitted work of the repository aside, untracked
+// files included: stashed under the label or, with asBranch, committed as
+// "WIP: label" on a new wip/ branch, which stays checked out. It returns what
+// it did.
+func secureWork(repoPath, label string, asBranch bool) (string, error) {
+	if !asBranch {
+		if _, err := gitOutput(repoPath, "stash", "push", "--quiet", "--include-untracked", "--message", label); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("stashed as %q", label), nil
+	}
+
+	branch := "wip/" + time.Now().Format("20060102-150405")
+	steps := [][]string{
+		{"switch", "--quiet", "--create", branch},
+		{"add", "--all"},
+		{"commit", "--quiet", "--no-verify", "--message", "WIP: " + label},
+	}
+	if wipPush {
+		steps = append(steps, []string{"push", "--quiet", "--set-upstream", "origin", branch})
+	}
+	for _, step := range steps {
+		if _, err := gitOutput(repoPath, step...); err != nil {
+			return "", err
+		}
+	}
+	if wipPush {
+		return fmt.Sprintf("committed and pushed on %s", branch), nil
+	}
+	return fmt.Sprintf("committed on %s", branch), nil
+}
+
+// visitWIP is the (w)ip action: w stashes the work of the project, w b commits
+// it on a WIP branch. With dryRun it only tells which.
+func visitWIP(projectPath string, parts []string, dryRun bool) {
+	asBranch := len(parts) > 1 && (parts[1] == "b" || parts[1] == "branch")
+	if dryRun {
+		if asBranch {
+			fmt.Printf("%s: would commit the work on a wip/ branch\n", displayName(projectPath))
+		} else {
+			fmt.Printf("%s: would stash the work\n", displayName(projectPath))
+		}
+		return
+	}
+	done, err := secureWork(projectPath, "gori wip "+time.Now().Format(time.DateTime), asBranch)
+	if err != nil {
+		fmt.Printf(tr("Error: %s\n"), err)
+		return
+	}
+	fmt.Printf("%s: %s\n", displayName(projectPath), done)
+}
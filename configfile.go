@@ -0,0 +1,142 @@
+package gori
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/encoding/yaml"
+	"github.com/go-git/go-git/v5/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// ConfigExtensions are the formats gori's config and ignore files can be
+// written in, in order of preference when several exist
+var ConfigExtensions = []string{".cue", ".json", ".yaml", ".yml"}
+
+// findConfigFile returns the first existing file called name in dir with one
+// of the ConfigExtensions, or the CUE one when none exists
+func findConfigFile(dir string, name string) string {
+	for _, ext := range ConfigExtensions {
+		candidate := filepath.Join(dir, name+ext)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+	}
+	return filepath.Join(dir, name+ConfigExtensions[0])
+}
+
+// configMu guards the CUE context shared by every config file gori reads and
+// writes, creating one for each is costly. A cue.Context is not safe for
+// concurrent use, so it and the values built with it are only used while
+// holding configMu.
+var configMu sync.Mutex
+
+var (
+	configContext   *cue.Context
+	compiledConfigs = map[string]compiledConfig{}
+)
+
+// compiledConfig is the outcome of compiling a config file with the given
+// content
+type compiledConfig struct {
+	content []byte
+	val     cue.Value
+	err     error
+}
+
+// sharedContext returns the shared CUE context, the caller holds configMu
+func sharedContext() *cue.Context {
+	if configContext == nil {
+		configContext = cuecontext.New()
+	}
+	return configContext
+}
+
+// compileConfig compiles the content of a config file with the shared
+// context, reusing what the same content of the same file compiled to before.
+// The caller holds configMu.
+func compileConfig(configFile string, content []byte) (cue.Value, error) {
+	if c, ok := compiledConfigs[configFile]; ok && bytes.Equal(c.content, content) {
+		return c.val, c.err
+	}
+	val, err := compileConfigFile(sharedContext(), configFile, content)
+	compiledConfigs[configFile] = compiledConfig{content: content, val: val, err: err}
+	return val, err
+}
+
+// compileConfigFile compiles a CUE, JSON or YAML file, told apart by its
+// extension
+func compileConfigFile(ctx *cue.Context, configFile string, content []byte) (cue.Value, error) {
+	switch filepath.Ext(configFile) {
+	case ".yaml", ".yml":
+		file, err := yaml.Extract(configFile, content)
+		if err != nil {
+			return cue.Value{}, err
+		}
+		val := ctx.BuildFile(file)
+		return val, val.Err()
+	default:
+		// JSON is valid CUE, so both compile the same way
+		val := ctx.CompileBytes(content, cue.Filename(configFile))
+		return val, val.Err()
+	}
+}
+
+// encodeConfigFile renders v in the format of configFile
+func encodeConfigFile(configFile string, v any) ([]byte, error) {
+	configMu.Lock()
+	defer configMu.Unlock()
+	switch filepath.Ext(configFile) {
+	case ".json":
+		b, err := json.MarshalIndent(v, "", "  ")
+		return append(b, '\n'), err
+	case ".yaml", ".yml":
+		val := sharedContext().Encode(v)
+		if val.Err() != nil {
+			return nil, val.Err()
+		}
+		return yaml.Encode(val)
+	case ".cue":
+		val := sharedContext().Encode(v)
+		if val.Err() != nil {
+			return nil, val.Err()
+		}
+		// write the fields at the top level instead of as one struct
+		node := val.Syntax()
+		if st, ok := node.(*ast.StructLit); ok {
+			node = &ast.File{Decls: st.Elts}
+		}
+		return format.Node(node)
+	default:
+		return nil, fmt.Errorf("unsupported config format %q", filepath.Ext(configFile))
+	}
+}
+
+// lineDiff renders the changed lines between two versions of a file, prefixed
+// by - and +
+func lineDiff(file string, old string, new string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s (dry run)\n", file, file)
+	for _, d := range diff.Do(old, new) {
+		prefix := map[diffmatchpatch.Operation]string{
+			diffmatchpatch.DiffDelete: "-",
+			diffmatchpatch.DiffInsert: "+",
+		}[d.Type]
+		if prefix == "" {
+			continue
+		}
+		for _, line := range strings.SplitAfter(strings.TrimSuffix(d.Text, "\n"), "\n") {
+			fmt.Fprintf(&b, "%s%s\n", prefix, strings.TrimSuffix(line, "\n"))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,99 @@
+package gori
+
+import (
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// tokenSource is a place a hosting token can be found
+type tokenSource struct {
+	// env is the environment variable holding the token
+	env string
+	// command prints the token, it is only run when installed
+	command []string
+}
+
+func (s tokenSource) String() string {
+	if s.env != "" {
+		return "$" + s.env
+	}
+	return strings.Join(s.command, " ")
+}
+
+// tokenSources lists where the token of each hosting type is looked for, in
+// order of precedence: tokenEnv, the variable configured for the host, and
+// then the environment variables the hosting providers' own CLIs use and the
+// token the CLIs are logged in with.
+func tokenSources(hostingType, host, tokenEnv string) []tokenSource {
+	var sources []tokenSource
+	if tokenEnv != "" {
+		sources = append(sources, tokenSource{env: tokenEnv})
+	}
+	return append(sources, typeTokenSources(hostingType, host)...)
+}
+
+// typeTokenSources are the sources of the hosting type not configured for the
+// host. GITLAB_TOKEN is meant for gitlab.com, other GitLab instances get the
+// token glab is logged in with there. Gitea and Forgejo have no CLI and no
+// variable meant for one instance, so hosts of theirs only get a token through
+// token_env: otherwise one meant for a self-hosted instance would be sent to
+// codeberg.org as well.
+func typeTokenSources(hostingType, host string) []tokenSource {
+	switch hostingType {
+	case "github":
+		if host == "github.com" {
+			return []tokenSource{
+				{env: "GH_TOKEN"},
+				{env: "GITHUB_TOKEN"},
+				{command: []string{"gh", "auth", "token", "--hostname", host}},
+			}
+		}
+		return []tokenSource{
+			{env: "GH_ENTERPRISE_TOKEN"},
+			{env: "GITHUB_ENTERPRISE_TOKEN"},
+			{command: []string{"gh", "auth", "token", "--hostname", host}},
+		}
+	case "gitlab":
+		if host == "gitlab.com" {
+			return []tokenSource{
+				{env: "GITLAB_TOKEN"},
+				{command: []string{"glab", "config", "get", "token", "--host", host}},
+			}
+		}
+		return []tokenSource{
+			{command: []string{"glab", "config", "get", "token", "--host", host}},
+		}
+	}
+	return nil
+}
+
+// commandOutput runs a command, it is replaced in tests
+var commandOutput = func(name string, args ...string) (string, error) {
+	if _, err := exec.LookPath(name); err != nil {
+		return "", err
+	}
+	out, err := exec.Command(name, args...).Output()
+	return string(out), err
+}
+
+// HostingToken finds the token for the hosting provider of the type at host,
+// from tokenEnv, the environment or the provider's CLI. It returns an empty
+// string when there is none, requests are made without authentication then.
+func HostingToken(hostingType, host, tokenEnv string) string {
+	for _, source := range tokenSources(hostingType, host, tokenEnv) {
+		var token string
+		if source.env != "" {
+			token = os.Getenv(source.env)
+		} else if out, err := commandOutput(source.command[0], source.command[1:]...); err == nil {
+			token = strings.TrimSpace(out)
+		}
+		if token != "" {
+			slog.Debug("found hosting token", "host", host, "source", source.String())
+			return token
+		}
+	}
+	slog.Debug("no hosting token", "host", host)
+	return ""
+}
@@ -0,0 +1,54 @@
+package gori
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestHostingToken(t *testing.T) {
+	original := commandOutput
+	t.Cleanup(func() { commandOutput = original })
+	commandOutput = func(name string, args ...string) (string, error) {
+		switch strings.Join(append([]string{name}, args...), " ") {
+		case "gh auth token --hostname github.com":
+			return "gh-cli\n", nil
+		case "glab config get token --host gitlab.example.com":
+			return "glab-cli\n", nil
+		}
+		return "", errors.New("not installed")
+	}
+
+	tests := []struct {
+		name        string
+		hostingType string
+		host        string
+		tokenEnv    string
+		env         map[string]string
+		want        string
+	}{
+		{name: "gh cli", hostingType: "github", host: "github.com", want: "gh-cli"},
+		{name: "GITHUB_TOKEN over gh cli", hostingType: "github", host: "github.com", env: map[string]string{"GITHUB_TOKEN": "github"}, want: "github"},
+		{name: "GH_TOKEN over GITHUB_TOKEN", hostingType: "github", host: "github.com", env: map[string]string{"GITHUB_TOKEN": "github", "GH_TOKEN": "gh"}, want: "gh"},
+		{name: "enterprise", hostingType: "github", host: "ghe.example.com", env: map[string]string{"GH_TOKEN": "gh", "GH_ENTERPRISE_TOKEN": "ghe"}, want: "ghe"},
+		{name: "glab cli", hostingType: "gitlab", host: "gitlab.example.com", want: "glab-cli"},
+		{name: "GITLAB_TOKEN", hostingType: "gitlab", host: "gitlab.com", env: map[string]string{"GITLAB_TOKEN": "gitlab"}, want: "gitlab"},
+		{name: "GITLAB_TOKEN only for gitlab.com", hostingType: "gitlab", host: "gitlab.example.com", env: map[string]string{"GITLAB_TOKEN": "gitlab"}, want: "glab-cli"},
+		{name: "GITLAB_TOKEN not for an unknown instance", hostingType: "gitlab", host: "gitlab.other.com", env: map[string]string{"GITLAB_TOKEN": "gitlab"}},
+		{name: "gitlab token_env", hostingType: "gitlab", host: "gitlab.other.com", tokenEnv: "GITLAB_TOKEN", env: map[string]string{"GITLAB_TOKEN": "gitlab"}, want: "gitlab"},
+		{name: "forgejo without token_env", hostingType: "forgejo", host: "codeberg.org", env: map[string]string{"FORGEJO_TOKEN": "forgejo", "GITEA_TOKEN": "gitea"}},
+		{name: "gitea token_env", hostingType: "gitea", host: "gitea.example.com", tokenEnv: "WORK_GITEA_TOKEN", env: map[string]string{"WORK_GITEA_TOKEN": "work"}, want: "work"},
+		{name: "token_env over gh cli", hostingType: "github", host: "github.com", tokenEnv: "WORK_GITEA_TOKEN", env: map[string]string{"WORK_GITEA_TOKEN": "work"}, want: "work"},
+		{name: "none", hostingType: "gitlab", host: "gitlab.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range []string{"GH_TOKEN", "GITHUB_TOKEN", "GH_ENTERPRISE_TOKEN", "GITHUB_ENTERPRISE_TOKEN", "GITLAB_TOKEN", "GITEA_TOKEN", "FORGEJO_TOKEN", "WORK_GITEA_TOKEN"} {
+				t.Setenv(name, tt.env[name])
+			}
+			if got := HostingToken(tt.hostingType, tt.host, tt.tokenEnv); got != tt.want {
+				t.Errorf("HostingToken() = %v, expected = %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package gori
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Gitea looks up pull requests through the API of Gitea, or Forgejo
+type Gitea struct {
+	// BaseURL is the API's endpoint, e.g. https://codeberg.org/api/v1
+	BaseURL string
+	// Token authenticates the requests, without one only public
+	// repositories can be looked up
+	Token  string
+	Client *http.Client
+}
+
+// PullRequest looks up the pull requests with branch as their head. The API
+// cannot filter on the head branch, so only the most recently updated pull
+// requests are searched.
+func (g *Gitea) PullRequest(path string, branch string, head string) (*PullRequest, error) {
+	query := url.Values{"state": {"all"}, "sort": {"recentupdate"}, "limit": {"50"}}
+
+	var pulls []struct {
+		Number  int    `json:"number"`
+		HTMLURL string `json:"html_url"`
+		State   string `json:"state"`
+		Merged  bool   `json:"merged"`
+		Head    struct {
+			Ref  string `json:"ref"`
+			SHA  string `json:"sha"`
+			Repo *struct {
+				FullName string `json:"full_name"`
+			} `json:"repo"`
+		} `json:"head"`
+	}
+	if err := g.get(fmt.Sprintf("/repos/%s/pulls?%s", path, query.Encode()), &pulls); err != nil {
+		return nil, fmt.Errorf("looking up pull requests of %s: %w", branch, err)
+	}
+
+	var merged *PullRequest
+	for _, pull := range pulls {
+		// pull requests from forks have a branch of their own
+		if pull.Head.Ref != branch || (pull.Head.Repo != nil && !strings.EqualFold(pull.Head.Repo.FullName, path)) {
+			continue
+		}
+		switch {
+		case pull.State == "open":
+			return &PullRequest{Number: pull.Number, URL: pull.HTMLURL}, nil
+		case pull.Merged && pull.Head.SHA == head && merged == nil:
+			merged = &PullRequest{Number: pull.Number, URL: pull.HTMLURL, Merged: true}
+		}
+	}
+	return merged, nil
+}
+
+// Repository looks up the repository's default branch and whether it is
+// archived
+func (g *Gitea) Repository(path string) (*HostedRepository, error) {
+	var repo struct {
+		DefaultBranch string `json:"default_branch"`
+		Archived      bool   `json:"archived"`
+	}
+	if err := g.get("/repos/"+path, &repo); err != nil {
+		return nil, fmt.Errorf("looking up %s: %w", path, err)
+	}
+	return &HostedRepository{DefaultBranch: repo.DefaultBranch, Archived: repo.Archived}, nil
+}
+
+// CIStatus looks up the combined commit status, which Gitea and Forgejo
+// Actions report to as well
+func (g *Gitea) CIStatus(path string, commit string) (string, error) {
+	var status struct {
+		State      string `json:"state"`
+		TotalCount int    `json:"total_count"`
+	}
+	if err := g.get(fmt.Sprintf("/repos/%s/commits/%s/status", path, commit), &status); err != nil {
+		return "", fmt.Errorf("looking up the CI status of %s: %w", commit, err)
+	}
+	if status.TotalCount == 0 {
+		return "", nil
+	}
+	return commitStatusCI(status.State), nil
+}
+
+// get requests the API endpoint and decodes its JSON response into v
+func (g *Gitea) get(endpoint string, v any) error {
+	header := http.Header{}
+	if g.Token != "" {
+		header.Set("Authorization", "token "+g.Token)
+	}
+	return getJSON(g.Client, strings.TrimSuffix(g.BaseURL, "/")+endpoint, header, v)
+}
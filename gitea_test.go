@@ -0,0 +1,80 @@
+package gori
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGitea(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/repos/me/deleted" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("Authorization") != "token secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/api/v1/repos/me/tool/pulls":
+			fmt.Fprint(w, `[
+				{"number": 4, "html_url": "https://codeberg.org/me/tool/pulls/4", "state": "open", "head": {"ref": "feature", "sha": "fff", "repo": {"full_name": "fork/tool"}}},
+				{"number": 3, "html_url": "https://codeberg.org/me/tool/pulls/3", "state": "closed", "merged": true, "head": {"ref": "squashed", "sha": "ccc", "repo": {"full_name": "me/tool"}}},
+				{"number": 2, "html_url": "https://codeberg.org/me/tool/pulls/2", "state": "open", "head": {"ref": "feature", "sha": "bbb", "repo": {"full_name": "me/tool"}}}
+			]`)
+		case "/api/v1/repos/me/tool/commits/ccc/status":
+			fmt.Fprint(w, `{"state": "error", "total_count": 2}`)
+		case "/api/v1/repos/me/tool":
+			fmt.Fprint(w, `{"default_branch": "trunk", "archived": true}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	gitea := &Gitea{BaseURL: server.URL + "/api/v1", Token: "secret", Client: server.Client()}
+	tests := []struct {
+		branch string
+		head   string
+		want   *PullRequest
+	}{
+		// the fork's feature branch is not ours
+		{branch: "feature", head: "bbb", want: &PullRequest{Number: 2, URL: "https://codeberg.org/me/tool/pulls/2"}},
+		{branch: "squashed", head: "ccc", want: &PullRequest{Number: 3, URL: "https://codeberg.org/me/tool/pulls/3", Merged: true}},
+		{branch: "squashed", head: "ddd"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.branch+"@"+tt.head, func(t *testing.T) {
+			got, err := gitea.PullRequest("me/tool", tt.branch, tt.head)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("PullRequest() = %+v, expected = %+v", got, tt.want)
+			}
+		})
+	}
+
+	repo, err := gitea.Repository("me/tool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *repo != (HostedRepository{DefaultBranch: "trunk", Archived: true}) {
+		t.Errorf("Repository() = %+v, expected trunk and archived", repo)
+	}
+	if got, err := gitea.CIStatus("me/tool", "ccc"); err != nil || got != CIFailed {
+		t.Errorf("CIStatus() = %q, %v, expected = %q", got, err, CIFailed)
+	}
+	if _, err := gitea.Repository("me/deleted"); !errors.Is(err, ErrRepoNotFound) {
+		t.Errorf("Repository() error = %v, expected ErrRepoNotFound", err)
+	}
+	// private repositories are hidden the same way from a request without a
+	// token
+	anonymous := &Gitea{BaseURL: server.URL + "/api/v1", Client: server.Client()}
+	if _, err := anonymous.Repository("me/deleted"); !errors.Is(err, ErrNotFoundWithoutToken) {
+		t.Errorf("Repository() without token error = %v, expected ErrNotFoundWithoutToken", err)
+	}
+}
@@ -0,0 +1,107 @@
+package gori
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// GitHub looks up pull requests through the GitHub REST API
+type GitHub struct {
+	// BaseURL is the API's endpoint, https://api.github.com for github.com
+	BaseURL string
+	// Token authenticates the requests, without one only public
+	// repositories can be looked up and the rate limit is low
+	Token  string
+	Client *http.Client
+}
+
+// PullRequest looks up the pull requests with branch as their head
+func (g *GitHub) PullRequest(path string, branch string, head string) (*PullRequest, error) {
+	owner, _, _ := strings.Cut(path, "/")
+	query := url.Values{"head": {owner + ":" + branch}, "state": {"all"}}
+
+	var pulls []struct {
+		Number   int     `json:"number"`
+		HTMLURL  string  `json:"html_url"`
+		State    string  `json:"state"`
+		MergedAt *string `json:"merged_at"`
+		Head     struct {
+			SHA string `json:"sha"`
+		} `json:"head"`
+	}
+	if err := g.get(fmt.Sprintf("/repos/%s/pulls?%s", path, query.Encode()), &pulls); err != nil {
+		return nil, fmt.Errorf("looking up pull requests of %s: %w", branch, err)
+	}
+
+	var merged *PullRequest
+	for _, pull := range pulls {
+		switch {
+		case pull.State == "open":
+			return &PullRequest{Number: pull.Number, URL: pull.HTMLURL}, nil
+		case pull.MergedAt != nil && pull.Head.SHA == head && merged == nil:
+			merged = &PullRequest{Number: pull.Number, URL: pull.HTMLURL, Merged: true}
+		}
+	}
+	return merged, nil
+}
+
+// Repository looks up the repository's default branch and whether it is
+// archived
+func (g *GitHub) Repository(path string) (*HostedRepository, error) {
+	var repo struct {
+		DefaultBranch string `json:"default_branch"`
+		Archived      bool   `json:"archived"`
+	}
+	if err := g.get("/repos/"+path, &repo); err != nil {
+		return nil, fmt.Errorf("looking up %s: %w", path, err)
+	}
+	return &HostedRepository{DefaultBranch: repo.DefaultBranch, Archived: repo.Archived}, nil
+}
+
+// CIStatus combines the commit statuses and the check runs, like those of
+// GitHub Actions, of the commit
+func (g *GitHub) CIStatus(path string, commit string) (string, error) {
+	var status struct {
+		State      string `json:"state"`
+		TotalCount int    `json:"total_count"`
+	}
+	if err := g.get(fmt.Sprintf("/repos/%s/commits/%s/status", path, commit), &status); err != nil {
+		return "", fmt.Errorf("looking up the CI status of %s: %w", commit, err)
+	}
+	var checks struct {
+		CheckRuns []struct {
+			Status     string `json:"status"`
+			Conclusion string `json:"conclusion"`
+		} `json:"check_runs"`
+	}
+	if err := g.get(fmt.Sprintf("/repos/%s/commits/%s/check-runs", path, commit), &checks); err != nil {
+		return "", fmt.Errorf("looking up the CI checks of %s: %w", commit, err)
+	}
+
+	var outcomes []string
+	if status.TotalCount > 0 {
+		outcomes = append(outcomes, commitStatusCI(status.State))
+	}
+	for _, run := range checks.CheckRuns {
+		switch {
+		case run.Status != "completed":
+			outcomes = append(outcomes, CIPending)
+		case run.Conclusion == "success" || run.Conclusion == "neutral" || run.Conclusion == "skipped":
+			outcomes = append(outcomes, CIPassed)
+		default:
+			outcomes = append(outcomes, CIFailed)
+		}
+	}
+	return combineCI(outcomes...), nil
+}
+
+// get requests the API endpoint and decodes its JSON response into v
+func (g *GitHub) get(endpoint string, v any) error {
+	header := http.Header{"Accept": {"application/vnd.github+json"}}
+	if g.Token != "" {
+		header.Set("Authorization", "Bearer "+g.Token)
+	}
+	return getJSON(g.Client, strings.TrimSuffix(g.BaseURL, "/")+endpoint, header, v)
+}
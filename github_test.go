@@ -0,0 +1,93 @@
+package gori
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGitHubPullRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/hansbogert/gori/pulls" || r.URL.Query().Get("state") != "all" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Query().Get("head") {
+		case "hansbogert:feature":
+			fmt.Fprint(w, `[
+				{"number": 120, "html_url": "https://github.com/hansbogert/gori/pull/120", "state": "closed", "merged_at": "2026-01-01T00:00:00Z", "head": {"sha": "aaa"}},
+				{"number": 123, "html_url": "https://github.com/hansbogert/gori/pull/123", "state": "open", "head": {"sha": "bbb"}}
+			]`)
+		case "hansbogert:squashed":
+			fmt.Fprint(w, `[
+				{"number": 99, "html_url": "https://github.com/hansbogert/gori/pull/99", "state": "closed", "merged_at": null, "head": {"sha": "ccc"}},
+				{"number": 100, "html_url": "https://github.com/hansbogert/gori/pull/100", "state": "closed", "merged_at": "2026-01-01T00:00:00Z", "head": {"sha": "ccc"}}
+			]`)
+		default:
+			fmt.Fprint(w, `[]`)
+		}
+	}))
+	defer server.Close()
+
+	github := &GitHub{BaseURL: server.URL, Token: "secret", Client: server.Client()}
+	tests := []struct {
+		branch string
+		head   string
+		want   *PullRequest
+	}{
+		{branch: "feature", head: "bbb", want: &PullRequest{Number: 123, URL: "https://github.com/hansbogert/gori/pull/123"}},
+		{branch: "squashed", head: "ccc", want: &PullRequest{Number: 100, URL: "https://github.com/hansbogert/gori/pull/100", Merged: true}},
+		// commits added after the merge are not upstreamed
+		{branch: "squashed", head: "ddd"},
+		{branch: "wip", head: "eee"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.branch+"@"+tt.head, func(t *testing.T) {
+			got, err := github.PullRequest("hansbogert/gori", tt.branch, tt.head)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("PullRequest() = %+v, expected = %+v", got, tt.want)
+			}
+		})
+	}
+
+	github.Token = ""
+	if _, err := github.PullRequest("hansbogert/gori", "feature", "bbb"); err == nil {
+		t.Errorf("PullRequest() without token succeeded, expected 401")
+	}
+}
+
+func TestGitHubCIStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/me/tool/commits/green/status", "/repos/me/tool/commits/none/status":
+			fmt.Fprint(w, `{"state": "pending", "total_count": 0}`)
+		case "/repos/me/tool/commits/red/status":
+			fmt.Fprint(w, `{"state": "failure", "total_count": 1}`)
+		case "/repos/me/tool/commits/green/check-runs":
+			fmt.Fprint(w, `{"check_runs": [{"status": "completed", "conclusion": "success"}, {"status": "completed", "conclusion": "skipped"}]}`)
+		case "/repos/me/tool/commits/red/check-runs":
+			fmt.Fprint(w, `{"check_runs": [{"status": "in_progress"}]}`)
+		case "/repos/me/tool/commits/none/check-runs":
+			fmt.Fprint(w, `{"check_runs": []}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	github := &GitHub{BaseURL: server.URL, Client: server.Client()}
+	for commit, want := range map[string]string{"green": CIPassed, "red": CIFailed, "none": ""} {
+		got, err := github.CIStatus("me/tool", commit)
+		if err != nil || got != want {
+			t.Errorf("CIStatus(%s) = %q, %v, expected = %q", commit, got, err, want)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package gori
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// GitLab looks up merge requests through the GitLab REST API
+type GitLab struct {
+	// BaseURL is the API's endpoint, https://gitlab.com/api/v4 for gitlab.com
+	BaseURL string
+	// Token authenticates the requests, without one only public projects
+	// can be looked up
+	Token  string
+	Client *http.Client
+}
+
+// PullRequest looks up the merge requests with branch as their source
+func (g *GitLab) PullRequest(path string, branch string, head string) (*PullRequest, error) {
+	query := url.Values{"source_branch": {branch}, "state": {"all"}}
+
+	var requests []struct {
+		IID    int    `json:"iid"`
+		WebURL string `json:"web_url"`
+		State  string `json:"state"`
+		SHA    string `json:"sha"`
+	}
+	if err := g.get(fmt.Sprintf("/projects/%s/merge_requests?%s", url.PathEscape(path), query.Encode()), &requests); err != nil {
+		return nil, fmt.Errorf("looking up merge requests of %s: %w", branch, err)
+	}
+
+	var merged *PullRequest
+	for _, request := range requests {
+		switch {
+		case request.State == "opened":
+			return &PullRequest{Number: request.IID, URL: request.WebURL, MergeRequest: true}, nil
+		case request.State == "merged" && request.SHA == head && merged == nil:
+			merged = &PullRequest{Number: request.IID, URL: request.WebURL, Merged: true, MergeRequest: true}
+		}
+	}
+	return merged, nil
+}
+
+// Repository looks up the project's default branch and whether it is
+// archived
+func (g *GitLab) Repository(path string) (*HostedRepository, error) {
+	var project struct {
+		DefaultBranch string `json:"default_branch"`
+		Archived      bool   `json:"archived"`
+	}
+	if err := g.get("/projects/"+url.PathEscape(path), &project); err != nil {
+		return nil, fmt.Errorf("looking up %s: %w", path, err)
+	}
+	return &HostedRepository{DefaultBranch: project.DefaultBranch, Archived: project.Archived}, nil
+}
+
+// CIStatus looks up the last pipeline of the commit
+func (g *GitLab) CIStatus(path string, commit string) (string, error) {
+	var details struct {
+		LastPipeline *struct {
+			Status string `json:"status"`
+		} `json:"last_pipeline"`
+	}
+	if err := g.get(fmt.Sprintf("/projects/%s/repository/commits/%s", url.PathEscape(path), commit), &details); err != nil {
+		return "", fmt.Errorf("looking up the CI status of %s: %w", commit, err)
+	}
+	if details.LastPipeline == nil {
+		return "", nil
+	}
+	switch details.LastPipeline.Status {
+	case "success":
+		return CIPassed, nil
+	case "failed", "canceled":
+		return CIFailed, nil
+	case "skipped", "manual":
+		return "", nil
+	}
+	return CIPending, nil
+}
+
+// get requests the API endpoint and decodes its JSON response into v
+func (g *GitLab) get(endpoint string, v any) error {
+	header := http.Header{}
+	if g.Token != "" {
+		header.Set("PRIVATE-TOKEN", g.Token)
+	}
+	return getJSON(g.Client, strings.TrimSuffix(g.BaseURL, "/")+endpoint, header, v)
+}
@@ -0,0 +1,76 @@
+package gori
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGitLabPullRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case "/api/v4/projects/group%2Fsub%2Fproject/repository/commits/bbb":
+			fmt.Fprint(w, `{"id": "bbb", "last_pipeline": {"status": "running"}}`)
+			return
+		case "/api/v4/projects/group%2Fsub%2Fproject/repository/commits/ccc":
+			fmt.Fprint(w, `{"id": "ccc", "last_pipeline": null}`)
+			return
+		}
+		if r.URL.EscapedPath() != "/api/v4/projects/group%2Fsub%2Fproject/merge_requests" || r.URL.Query().Get("state") != "all" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("PRIVATE-TOKEN") != "secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Query().Get("source_branch") {
+		case "feature":
+			fmt.Fprint(w, `[
+				{"iid": 11, "web_url": "https://gitlab.example.com/group/sub/project/-/merge_requests/11", "state": "merged", "sha": "aaa"},
+				{"iid": 12, "web_url": "https://gitlab.example.com/group/sub/project/-/merge_requests/12", "state": "opened", "sha": "bbb"}
+			]`)
+		case "squashed":
+			fmt.Fprint(w, `[
+				{"iid": 7, "web_url": "https://gitlab.example.com/group/sub/project/-/merge_requests/7", "state": "closed", "sha": "ccc"},
+				{"iid": 8, "web_url": "https://gitlab.example.com/group/sub/project/-/merge_requests/8", "state": "merged", "sha": "ccc"}
+			]`)
+		default:
+			fmt.Fprint(w, `[]`)
+		}
+	}))
+	defer server.Close()
+
+	gitlab := &GitLab{BaseURL: server.URL + "/api/v4", Token: "secret", Client: server.Client()}
+	tests := []struct {
+		branch string
+		head   string
+		want   *PullRequest
+	}{
+		{branch: "feature", head: "bbb", want: &PullRequest{Number: 12, URL: "https://gitlab.example.com/group/sub/project/-/merge_requests/12", MergeRequest: true}},
+		{branch: "squashed", head: "ccc", want: &PullRequest{Number: 8, URL: "https://gitlab.example.com/group/sub/project/-/merge_requests/8", Merged: true, MergeRequest: true}},
+		// commits added after the merge are not upstreamed
+		{branch: "squashed", head: "ddd"},
+		{branch: "wip", head: "eee"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.branch+"@"+tt.head, func(t *testing.T) {
+			got, err := gitlab.PullRequest("group/sub/project", tt.branch, tt.head)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("PullRequest() = %+v, expected = %+v", got, tt.want)
+			}
+		})
+	}
+	for commit, want := range map[string]string{"bbb": CIPending, "ccc": ""} {
+		if got, err := gitlab.CIStatus("group/sub/project", commit); err != nil || got != want {
+			t.Errorf("CIStatus(%s) = %q, %v, expected = %q", commit, got, err, want)
+		}
+	}
+	if got := (PullRequest{Number: 12, MergeRequest: true}).String(); got != "MR !12" {
+		t.Errorf("String() = %v, expected = MR !12", got)
+	}
+}
@@ -0,0 +1,49 @@
+package gori
+
+import (
+	"errors"
+	"path/filepath"
+
+	"github.com/go-git/go-billy/v5/osfs"
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing/cache"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+)
+
+// worktreeConfigExtension is turned on by git sparse-checkout, moving the
+// sparse settings to config.worktree. go-git refuses to open repositories
+// with it, though reading them without those settings is fine.
+const worktreeConfigExtension = "worktreeConfig"
+
+// OpenRepo opens the git repository at repoPath like git.PlainOpen, but also
+// opens the ones using the worktreeConfig extension, like sparse checkouts
+func OpenRepo(repoPath string) (*git.Repository, error) {
+	repo, err := git.PlainOpen(repoPath)
+	if !errors.Is(err, git.ErrUnsupportedExtensionRepositoryFormatVersion) && !errors.Is(err, git.ErrUnknownExtension) {
+		return repo, err
+	}
+
+	storage := filesystem.NewStorage(osfs.New(filepath.Join(repoPath, git.GitDirName)), cache.NewObjectLRUDefault())
+	if reopened, reopenErr := git.Open(worktreeConfigStorage{storage}, osfs.New(repoPath)); reopenErr == nil {
+		return reopened, nil
+	}
+	return nil, err
+}
+
+// worktreeConfigStorage hides the worktreeConfig extension from go-git, any
+// other extension it does not know still keeps the repository from opening
+type worktreeConfigStorage struct {
+	*filesystem.Storage
+}
+
+func (s worktreeConfigStorage) Config() (*config.Config, error) {
+	cfg, err := s.Storage.Config()
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Raw.HasSection("extensions") {
+		cfg.Raw.Section("extensions").RemoveOption(worktreeConfigExtension)
+	}
+	return cfg, nil
+}
@@ -0,0 +1,48 @@
+package gori
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	git "github.com/go-git/go-git/v5"
+)
+
+func TestOpenRepo(t *testing.T) {
+	tests := map[string]struct {
+		extension string
+		wantErr   bool
+	}{
+		"no extension":    {"", false},
+		"worktree config": {"worktreeConfig = true", false},
+		"unknown":         {"refStorage = reftable", true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			repoPath := t.TempDir()
+			if _, err := git.PlainInit(repoPath, false); err != nil {
+				t.Fatal(err)
+			}
+			if tt.extension != "" {
+				configPath := filepath.Join(repoPath, ".git", "config")
+				content, err := os.ReadFile(configPath)
+				if err != nil {
+					t.Fatal(err)
+				}
+				content = append(content, "[extensions]\n\t"+tt.extension+"\n"...)
+				if err := os.WriteFile(configPath, content, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			_, err := OpenRepo(repoPath)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("OpenRepo() error = %v, expected error = %v", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), "extension") {
+				t.Errorf("OpenRepo() error = %v, expected the go-git extension error", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package gori
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// PullRequest is the pull request, or merge request on GitLab, of a branch
+type PullRequest struct {
+	Number int    `json:"number"`
+	URL    string `json:"url"`
+	// Merged is set for a pull request merged with the branch's commit as its
+	// head, however it was merged: squashing or rebasing leaves the branch's
+	// commit out of the target branch, but its work is upstreamed all the same
+	Merged bool `json:"merged,omitempty"`
+	// MergeRequest is set for GitLab's merge requests
+	MergeRequest bool `json:"merge_request,omitempty"`
+}
+
+// String refers to the pull request the way its hosting provider does, e.g.
+// PR #123 or MR !12
+func (pr PullRequest) String() string {
+	if pr.MergeRequest {
+		return fmt.Sprintf("MR !%d", pr.Number)
+	}
+	return fmt.Sprintf("PR #%d", pr.Number)
+}
+
+// HostedRepository is what the hosting provider knows about a repository
+type HostedRepository struct {
+	DefaultBranch string
+	Archived      bool
+}
+
+// Hosting looks up what the hosting provider knows about a repository, given
+// its path on the host, e.g. hansbogert/gori
+type Hosting interface {
+	// PullRequest finds the pull request of branch: the open one, or else
+	// one merged with head, the branch's commit, as its head. It returns nil
+	// when there is neither.
+	PullRequest(path string, branch string, head string) (*PullRequest, error)
+	// Repository looks up the repository itself
+	Repository(path string) (*HostedRepository, error)
+	// CIStatus looks up the outcome of the CI of commit, one of CIPassed,
+	// CIFailed or CIPending, or empty when it has no CI
+	CIStatus(path string, commit string) (string, error)
+}
+
+// CI outcomes of a commit
+const (
+	CIPassed  = "passed"
+	CIFailed  = "failed"
+	CIPending = "pending"
+)
+
+// combineCI reduces the outcomes of several CI systems to one: any failure
+// fails the commit, any pending one keeps it pending
+func combineCI(outcomes ...string) string {
+	combined := ""
+	for _, outcome := range outcomes {
+		switch {
+		case outcome == CIFailed:
+			return CIFailed
+		case outcome == CIPending:
+			combined = CIPending
+		case outcome == CIPassed && combined == "":
+			combined = CIPassed
+		}
+	}
+	return combined
+}
+
+// commitStatusCI maps the state of a GitHub or Gitea combined commit status
+func commitStatusCI(state string) string {
+	switch state {
+	case "success":
+		return CIPassed
+	case "failure", "error":
+		return CIFailed
+	case "pending":
+		return CIPending
+	}
+	return ""
+}
+
+// HostingTypes lists the hosting software gori can query, forgejo being
+// gitea's fork with the same API
+var HostingTypes = []string{"github", "gitlab", "gitea", "forgejo"}
+
+// HostConfig tells gori which hosting software runs on a host, for
+// self-hosted instances
+type HostConfig struct {
+	Host string `json:"host"`
+	// Type is one of HostingTypes
+	Type string `json:"type"`
+	// API is the API's endpoint, by default the usual one of the type on the
+	// host
+	API string `json:"api,omitempty"`
+	// TokenEnv is the environment variable holding the token for the host,
+	// looked at before anything else
+	TokenEnv string `json:"token_env,omitempty"`
+}
+
+// defaultHosts are the public hosts gori knows without configuration
+var defaultHosts = []HostConfig{
+	{Host: "github.com", Type: "github", API: "https://api.github.com"},
+	{Host: "gitlab.com", Type: "gitlab"},
+	{Host: "codeberg.org", Type: "forgejo"},
+}
+
+// NewHosting creates the client for the hosting provider at host, configured
+// by hosts or one of the public hosts, making its requests with client. It
+// returns false for unknown hosts.
+func NewHosting(host string, hosts []HostConfig, client *http.Client) (Hosting, bool, error) {
+	for _, config := range append(hosts, defaultHosts...) {
+		if !strings.EqualFold(config.Host, host) {
+			continue
+		}
+		switch config.Type {
+		case "github":
+			api := config.API
+			if api == "" {
+				// GitHub Enterprise Server
+				api = "https://" + host + "/api/v3"
+			}
+			return &GitHub{BaseURL: api, Token: HostingToken(config.Type, host, config.TokenEnv), Client: client}, true, nil
+		case "gitlab":
+			api := config.API
+			if api == "" {
+				api = "https://" + host + "/api/v4"
+			}
+			return &GitLab{BaseURL: api, Token: HostingToken(config.Type, host, config.TokenEnv), Client: client}, true, nil
+		case "gitea", "forgejo":
+			api := config.API
+			if api == "" {
+				api = "https://" + host + "/api/v1"
+			}
+			return &Gitea{BaseURL: api, Token: HostingToken(config.Type, host, config.TokenEnv), Client: client}, true, nil
+		default:
+			return nil, false, fmt.Errorf("unknown hosting type %q for %s, use one of %v", config.Type, host, HostingTypes)
+		}
+	}
+	return nil, false, nil
+}
+
+// ErrRepoNotFound is returned when the hosting provider does not know the
+// repository, even though the request carried a token: it was deleted
+var ErrRepoNotFound = errors.New("repository not found")
+
+// ErrNotFoundWithoutToken is returned when the hosting provider does not know
+// the repository, asked without a token. Providers hide private repositories
+// that way, so whether it was deleted is unknown.
+var ErrNotFoundWithoutToken = errors.New("repository not found, private repositories need a token")
+
+// HostedRepo splits a remote URL into the host and the repository's path on
+// it, e.g. github.com and hansbogert/gori. It returns false for remotes which
+// are not hosted, like local paths.
+func HostedRepo(remoteURL string) (host string, path string, ok bool) {
+	normalized := NormalizeRemoteURL(remoteURL)
+	if strings.HasPrefix(normalized, "/") || strings.HasPrefix(normalized, ".") || isWindowsPath(remoteURL) {
+		return "", "", false
+	}
+	host, path, ok = strings.Cut(normalized, "/")
+	if !ok || !strings.Contains(host, ".") || !strings.Contains(path, "/") {
+		return "", "", false
+	}
+	return host, path, true
+}
+
+// getJSON requests url with the headers and decodes its JSON response into v
+func getJSON(client *http.Client, url string, header http.Header, v any) error {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		if header.Get("Authorization") == "" && header.Get("PRIVATE-TOKEN") == "" {
+			return fmt.Errorf("GET %s: %w", req.URL.Path, ErrNotFoundWithoutToken)
+		}
+		return fmt.Errorf("GET %s: %w", req.URL.Path, ErrRepoNotFound)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", req.URL.Path, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
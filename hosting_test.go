@@ -0,0 +1,89 @@
+package gori
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestHostedRepo(t *testing.T) {
+	tests := []struct {
+		url      string
+		wantHost string
+		wantPath string
+		wantOK   bool
+	}{
+		{url: "git@github.com:hansbogert/gori.git", wantHost: "github.com", wantPath: "hansbogert/gori", wantOK: true},
+		{url: "https://gitlab.example.com/group/sub/project", wantHost: "gitlab.example.com", wantPath: "group/sub/project", wantOK: true},
+		{url: "/srv/git/gori.git"},
+		{url: "../upstream"},
+		{url: "https://localhost/gori"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			host, path, ok := HostedRepo(tt.url)
+			if host != tt.wantHost || path != tt.wantPath || ok != tt.wantOK {
+				t.Errorf("HostedRepo() = %v, %v, %v, expected = %v, %v, %v", host, path, ok, tt.wantHost, tt.wantPath, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestNewHosting(t *testing.T) {
+	hosts := []HostConfig{
+		{Host: "git.example.com", Type: "gitlab"},
+		{Host: "ghe.example.com", Type: "github"},
+		{Host: "odd.example.com", Type: "svn"},
+	}
+	tests := []struct {
+		host    string
+		want    Hosting
+		wantErr bool
+	}{
+		{host: "github.com", want: &GitHub{BaseURL: "https://api.github.com"}},
+		{host: "GitLab.com", want: &GitLab{BaseURL: "https://GitLab.com/api/v4"}},
+		{host: "git.example.com", want: &GitLab{BaseURL: "https://git.example.com/api/v4"}},
+		{host: "ghe.example.com", want: &GitHub{BaseURL: "https://ghe.example.com/api/v3"}},
+		{host: "codeberg.org", want: &Gitea{BaseURL: "https://codeberg.org/api/v1"}},
+		{host: "odd.example.com", wantErr: true},
+		{host: "unknown.example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			got, ok, err := NewHosting(tt.host, hosts, http.DefaultClient)
+			if (err != nil) != tt.wantErr || ok != (tt.want != nil) {
+				t.Fatalf("NewHosting() = %v, %v, %v", got, ok, err)
+			}
+			switch want := tt.want.(type) {
+			case *GitHub:
+				if g, isGitHub := got.(*GitHub); !isGitHub || g.BaseURL != want.BaseURL {
+					t.Errorf("NewHosting() = %+v, expected = %+v", got, want)
+				}
+			case *GitLab:
+				if g, isGitLab := got.(*GitLab); !isGitLab || g.BaseURL != want.BaseURL {
+					t.Errorf("NewHosting() = %+v, expected = %+v", got, want)
+				}
+			case *Gitea:
+				if g, isGitea := got.(*Gitea); !isGitea || g.BaseURL != want.BaseURL {
+					t.Errorf("NewHosting() = %+v, expected = %+v", got, want)
+				}
+			}
+		})
+	}
+}
+
+func Test_combineCI(t *testing.T) {
+	tests := []struct {
+		outcomes []string
+		want     string
+	}{
+		{outcomes: nil, want: ""},
+		{outcomes: []string{CIPassed, ""}, want: CIPassed},
+		{outcomes: []string{CIPassed, CIPending}, want: CIPending},
+		{outcomes: []string{CIPending, CIFailed, CIPassed}, want: CIFailed},
+	}
+	for _, tt := range tests {
+		if got := combineCI(tt.outcomes...); got != tt.want {
+			t.Errorf("combineCI(%v) = %q, expected = %q", tt.outcomes, got, tt.want)
+		}
+	}
+}
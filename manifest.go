@@ -0,0 +1,115 @@
+package gori
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/encoding/gocode/gocodec"
+)
+
+// ManifestFormats lists the formats a manifest can be encoded in
+var ManifestFormats = []string{"cue", "json"}
+
+// Manifest describes the repositories of a workspace, so it can be rebuilt
+// elsewhere
+type Manifest struct {
+	Repos []ManifestRepo `json:"repos"`
+}
+
+// ManifestRepo describes a single repository of a workspace
+type ManifestRepo struct {
+	// Path is relative to the scan root
+	Path string `json:"path"`
+	// Remotes maps remote names to their URL
+	Remotes map[string]string `json:"remotes,omitempty"`
+	// DefaultBranch is the branch the origin's HEAD points at
+	DefaultBranch string `json:"default_branch,omitempty"`
+	// Branch is the checked out branch
+	Branch string `json:"branch,omitempty"`
+}
+
+// CloneURL returns the URL to clone the repository from, preferring origin
+// and otherwise the alphabetically first remote
+func (r ManifestRepo) CloneURL() (remote string, url string) {
+	if url, ok := r.Remotes["origin"]; ok {
+		return "origin", url
+	}
+	for name, u := range r.Remotes {
+		if remote == "" || name < remote {
+			remote, url = name, u
+		}
+	}
+	return remote, url
+}
+
+// LoadManifest reads a manifest file, either CUE or JSON
+func LoadManifest(manifestFile string) (*Manifest, error) {
+	content, err := os.ReadFile(manifestFile)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", manifestFile, err)
+	}
+
+	// JSON is valid CUE, so both compile the same way
+	configMu.Lock()
+	defer configMu.Unlock()
+	val, err := compileConfig(manifestFile, content)
+	if err != nil {
+		return nil, fmt.Errorf("compiling %s: %w", manifestFile, err)
+	}
+
+	var manifest Manifest
+	if err := val.Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", manifestFile, err)
+	}
+
+	for _, repo := range manifest.Repos {
+		if err := validateManifestPath(repo.Path); err != nil {
+			return nil, fmt.Errorf("%s: %w", manifestFile, err)
+		}
+	}
+
+	return &manifest, nil
+}
+
+// validateManifestPath makes sure a repository path stays within the scan root
+func validateManifestPath(path string) error {
+	if path == "" {
+		return fmt.Errorf("repository without path")
+	}
+	clean := filepath.Clean(path)
+	if filepath.IsAbs(clean) || clean == "." || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("repository path %q must be relative and within the scan root", path)
+	}
+	return nil
+}
+
+// Encode renders the manifest as either CUE or JSON
+func (m *Manifest) Encode(encoding string) ([]byte, error) {
+	switch encoding {
+	case "json":
+		b, err := json.MarshalIndent(m, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("encoding manifest: %w", err)
+		}
+		return append(b, '\n'), nil
+	case "cue":
+		configMu.Lock()
+		defer configMu.Unlock()
+		codec := gocodec.New(sharedContext(), nil)
+		val, err := codec.Decode(m)
+		if err != nil {
+			return nil, fmt.Errorf("decoding manifest: %w", err)
+		}
+		b, err := format.Node(val.Syntax())
+		if err != nil {
+			return nil, fmt.Errorf("formatting CUE: %w", err)
+		}
+		return b, nil
+	default:
+		return nil, fmt.Errorf("unknown manifest format %q, use one of %v", encoding, ManifestFormats)
+	}
+}
@@ -0,0 +1,138 @@
+package gori
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// IgnoreConfigVersion is the current layout of the ignore files
+//
+//  1. repositories are identified by their path
+//  2. repositories are identified by their origin URL, falling back to the path
+const IgnoreConfigVersion = 2
+
+// migrations upgrade an ignore config from the version at their index + 1 to
+// the next one
+var migrations = []func(config *IgnoreConfig, scanPath string){
+	addRepoURLs,
+}
+
+// MigrateIgnoreConfig upgrades the ignore file of the scan path to the current
+// layout. It returns the version the file had, the file is left alone when it
+// is already up to date. With dryRun the changes are printed instead.
+func MigrateIgnoreConfig(scanPath string, dryRun bool) (int, error) {
+	config, err := LoadIgnoreConfig(scanPath)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := checkWritable(config, IgnoreFile(scanPath)); err != nil {
+		return 0, err
+	}
+	from := max(config.Version, 1)
+	if from == IgnoreConfigVersion {
+		return from, nil
+	}
+	for _, migrate := range migrations[from-1:] {
+		migrate(config, scanPath)
+	}
+	config.Version = IgnoreConfigVersion
+
+	if err := writeIgnoreConfig(config, scanPath, dryRun); err != nil {
+		return from, fmt.Errorf("writing ignore file: %w", err)
+	}
+	return from, nil
+}
+
+// addRepoURLs records the origin URL of every entry pointing at a repository,
+// so its snoozes survive moving it
+func addRepoURLs(config *IgnoreConfig, scanPath string) {
+	for i, repo := range config.Repos {
+		if repo.URL != "" {
+			continue
+		}
+		config.Repos[i].URL = OriginURL(filepath.Join(scanPath, repo.Path))
+	}
+}
+
+// MovedRepo is an entry of the ignore file whose repository moved: its origin
+// URL is that of a repository found at another path, while nothing is left at
+// its own
+type MovedRepo struct {
+	// From is the path of the entry, To the one of the repository now, as
+	// stored in the ignore file
+	From string
+	To   string
+}
+
+// MovedRepos finds the entries of the scan root's ignore file whose repository
+// moved, among the repositories found by path with their origin URL. Their
+// snoozes still apply by URL, but the file no longer tells where the
+// repository is, and would not once it lacks a URL.
+func MovedRepos(scanPath string, remotes map[string]string) ([]MovedRepo, error) {
+	config, err := LoadIgnoreConfig(scanPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	byURL := reposByURL(remotes)
+	resolver := NewPathResolver(scanPath)
+	var moved []MovedRepo
+	for _, repo := range config.Repos {
+		if repo.URL == "" || strings.ContainsAny(repo.Path, "*?[") {
+			continue
+		}
+		if _, err := os.Stat(resolver.Resolve(repo.Path)); !errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		// a repository cloned twice cannot tell which one is meant
+		if found := byURL[NormalizeRemoteURL(repo.URL)]; len(found) == 1 {
+			moved = append(moved, MovedRepo{From: repo.Path, To: resolver.Stored(found[0])})
+		}
+	}
+	slices.SortFunc(moved, func(a, b MovedRepo) int { return cmp.Compare(a.From, b.From) })
+	return moved, nil
+}
+
+// reposByURL groups the paths of the repositories by their normalized origin
+// URL, leaving out those without one
+func reposByURL(remotes map[string]string) map[string][]string {
+	byURL := map[string][]string{}
+	for repoPath, remote := range remotes {
+		if remote != "" {
+			byURL[NormalizeRemoteURL(remote)] = append(byURL[NormalizeRemoteURL(remote)], repoPath)
+		}
+	}
+	return byURL
+}
+
+// MoveRepoEntries points the entries of the moved repositories at their new
+// path, recording each move in the snooze log
+func MoveRepoEntries(scanPath string, moved []MovedRepo) error {
+	config, err := LoadIgnoreConfig(scanPath)
+	if err != nil {
+		return err
+	}
+	for i, repo := range config.Repos {
+		for _, m := range moved {
+			if repo.Path == m.From {
+				config.Repos[i].Path = m.To
+			}
+		}
+	}
+	if err := writeIgnoreConfig(config, scanPath, false); err != nil {
+		return fmt.Errorf("writing ignore file: %w", err)
+	}
+	for _, m := range moved {
+		logSnoozeChange(scanPath, SnoozeLogEntry{Action: "move", Repo: m.To, Check: "all", Reason: "moved from " + m.From})
+	}
+	return nil
+}
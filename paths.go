@@ -0,0 +1,106 @@
+package gori
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// PathResolver puts the paths of repositories in the form each use needs:
+// stored in an ignore file relative to its directory with forward slashes,
+// matched as absolute paths with symlinks resolved, and shown relative to the
+// scan root. Going through one resolver keeps an entry written while scanning
+// from one directory matching when scanning from another.
+type PathResolver struct {
+	// Base is the absolute directory stored paths are relative to, the
+	// directory of the ignore file
+	Base string
+	// FoldCase matches paths regardless of case, as Windows does
+	FoldCase bool
+}
+
+// NewPathResolver returns the resolver of paths relative to base, folding
+// case on Windows
+func NewPathResolver(base string) PathResolver {
+	return PathResolver{Base: AbsPath(base), FoldCase: runtime.GOOS == "windows"}
+}
+
+// AbsPath returns the absolute, clean path with its symlinks resolved, so the
+// same repository reached through a symlink has the same path. A path which
+// does not exist is only made absolute.
+func AbsPath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.Clean(path)
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		return resolved
+	}
+	return abs
+}
+
+// Rel returns the path of the repository relative to Base, or its absolute
+// path when it has none, e.g. on another drive
+func (p PathResolver) Rel(repoPath string) string {
+	abs := AbsPath(repoPath)
+	rel, err := filepath.Rel(p.Base, abs)
+	if err != nil {
+		return abs
+	}
+	return rel
+}
+
+// Stored returns the path to write to an ignore file for the repository,
+// with forward slashes so the file works on any platform
+func (p PathResolver) Stored(repoPath string) string {
+	return filepath.ToSlash(p.Rel(repoPath))
+}
+
+// Resolve returns the absolute path, or glob, of a stored path. Besides
+// relative paths, those of the global ignore file may be absolute or start
+// with ~/.
+func (p PathResolver) Resolve(stored string) string {
+	if home, err := os.UserHomeDir(); err == nil && (strings.HasPrefix(stored, "~/") || strings.HasPrefix(stored, `~\`)) {
+		stored = filepath.Join(home, stored[2:])
+	}
+	if !filepath.IsAbs(stored) {
+		stored = filepath.Join(p.Base, stored)
+	}
+	return filepath.Clean(stored)
+}
+
+// Match reports whether the stored path, or glob, names the repository, with
+// or without the symlinks on the way to either resolved
+func (p PathResolver) Match(stored string, repoPath string) bool {
+	pattern := p.Resolve(stored)
+	abs, err := filepath.Abs(repoPath)
+	if err != nil {
+		abs = repoPath
+	}
+	for _, pattern := range []string{pattern, evalPatternSymlinks(pattern)} {
+		for _, path := range []string{filepath.Clean(abs), AbsPath(repoPath)} {
+			if p.FoldCase {
+				pattern, path = strings.ToLower(pattern), strings.ToLower(path)
+			}
+			if matched, _ := filepath.Match(pattern, path); matched || pattern == path {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// evalPatternSymlinks resolves the symlinks of the directories of the glob up
+// to its first wildcard
+func evalPatternSymlinks(pattern string) string {
+	dir, rest := pattern, ""
+	for strings.ContainsAny(dir, "*?[") {
+		dir, rest = filepath.Dir(dir), filepath.Join(filepath.Base(dir), rest)
+	}
+	resolved, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return pattern
+	}
+	return filepath.Join(resolved, rest)
+}
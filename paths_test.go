@@ -0,0 +1,82 @@
+package gori
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// symlinkedTree makes real/repos/foo with link pointing at real, returning
+// the temporary directory holding both
+func symlinkedTree(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "real", "repos", "foo"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(dir, "real"), filepath.Join(dir, "link")); err != nil {
+		t.Skip("symlinks not supported:", err)
+	}
+	return dir
+}
+
+func TestPathResolver_Stored(t *testing.T) {
+	dir := symlinkedTree(t)
+	tests := map[string]struct {
+		base     string
+		repoPath string
+		want     string
+	}{
+		"below the base":           {"real", "real/repos/foo", "repos/foo"},
+		"base through a symlink":   {"link", "real/repos/foo", "repos/foo"},
+		"repo through a symlink":   {"real/repos", "link/repos/foo", "foo"},
+		"outside the base":         {"real/repos/foo", "real/repos", ".."},
+		"both through the symlink": {"link/repos", "link/repos/foo", "foo"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			resolver := NewPathResolver(filepath.Join(dir, tt.base))
+			if got := resolver.Stored(filepath.Join(dir, tt.repoPath)); got != tt.want {
+				t.Errorf("Stored() = %v, expected = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPathResolver_Match(t *testing.T) {
+	dir := symlinkedTree(t)
+	tests := map[string]struct {
+		base     string
+		stored   string
+		repoPath string
+		want     bool
+	}{
+		"relative":                     {"real/repos", "foo", "real/repos/foo", true},
+		"written through a symlink":    {"link/repos", "foo", "real/repos/foo", true},
+		"scanned through a symlink":    {"real/repos", "foo", "link/repos/foo", true},
+		"glob":                         {"real/repos", "f*", "link/repos/foo", true},
+		"absolute through a symlink":   {"real", filepath.Join(dir, "link", "repos", "foo"), "real/repos/foo", true},
+		"absolute glob through a link": {"real", filepath.Join(dir, "link", "*", "foo"), "real/repos/foo", true},
+		"other repository":             {"real/repos", "bar", "real/repos/foo", false},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			resolver := NewPathResolver(filepath.Join(dir, tt.base))
+			if got := resolver.Match(tt.stored, filepath.Join(dir, tt.repoPath)); got != tt.want {
+				t.Errorf("Match() = %v, expected = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPathResolver_MatchFoldCase(t *testing.T) {
+	dir := t.TempDir()
+	resolver := PathResolver{Base: dir, FoldCase: true}
+	if !resolver.Match("Foo", filepath.Join(dir, "foo")) {
+		t.Errorf("Match() = false, expected = true")
+	}
+	resolver.FoldCase = false
+	if resolver.Match("Foo", filepath.Join(dir, "foo")) {
+		t.Errorf("Match() = true, expected = false")
+	}
+}
@@ -0,0 +1,126 @@
+package gori
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"time"
+
+	"cuelang.org/go/cue"
+)
+
+// PolicySeverities are how bad violating a policy is, an error failing the
+// run
+var PolicySeverities = []string{"warning", "error"}
+
+// Policy is a rule repositories are held to, from policies in gori.cue. Its
+// require is CUE unified with the facts of a repository: one whose facts do
+// not fit, e.g. {upstreamed: true} | {days_since_commit: <=3}, violates it.
+type Policy struct {
+	// Name tells what the policy is about in its violations
+	Name string
+	// Path is the path or glob of the repositories it applies to, relative to
+	// the scan root, absolute or starting with ~/; all of them when empty
+	Path string
+	// Severity is one of PolicySeverities, warning by default
+	Severity string
+	require  cue.Value
+}
+
+// PolicyFacts are what is known of a repository when its policies are checked,
+// after its own checks applied and before snoozes
+type PolicyFacts struct {
+	Dirty      bool   `json:"dirty"`
+	Stashes    int    `json:"stashes"`
+	Upstreamed bool   `json:"upstreamed"`
+	Remote     string `json:"remote"`
+	// DaysSinceCommit is how many whole days ago the last commit was made,
+	// nil when unknown
+	DaysSinceCommit *int `json:"days_since_commit"`
+}
+
+// NewPolicyFacts returns the facts of the project
+func NewPolicyFacts(project ProjectStatus) PolicyFacts {
+	facts := PolicyFacts{Dirty: project.IsDirty, Stashes: project.Stashes, Upstreamed: project.Upstreamed, Remote: project.RemoteURL}
+	if !project.LastCommit.IsZero() {
+		days := int(time.Since(project.LastCommit) / (24 * time.Hour))
+		facts.DaysSinceCommit = &days
+	}
+	return facts
+}
+
+// LoadPolicies reads the policies of the user's gori.cue. Their require is
+// kept as CUE, which is why they are not part of Settings.
+func LoadPolicies() ([]Policy, error) {
+	settingsFile, err := SettingsPath()
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(settingsFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", settingsFile, err)
+	}
+
+	configMu.Lock()
+	defer configMu.Unlock()
+	val, err := compileConfig(settingsFile, content)
+	if err != nil {
+		return nil, fmt.Errorf("compiling %s: %w", settingsFile, err)
+	}
+	list := val.LookupPath(cue.ParsePath("policies"))
+	if !list.Exists() {
+		return nil, nil
+	}
+	iter, err := list.List()
+	if err != nil {
+		return nil, fmt.Errorf("%s: policies: %w", settingsFile, err)
+	}
+
+	var policies []Policy
+	for i := 0; iter.Next(); i++ {
+		policy := Policy{Name: fmt.Sprintf("policy %d", i+1), Severity: PolicySeverities[0]}
+		for field, dest := range map[string]*string{"name": &policy.Name, "path": &policy.Path, "severity": &policy.Severity} {
+			if v := iter.Value().LookupPath(cue.ParsePath(field)); v.Exists() {
+				if *dest, err = v.String(); err != nil {
+					return nil, fmt.Errorf("%s: policies: %w", settingsFile, err)
+				}
+			}
+		}
+		if !slices.Contains(PolicySeverities, policy.Severity) {
+			return nil, fmt.Errorf("%s: %s: unknown severity %q, use one of %v", settingsFile, policy.Name, policy.Severity, PolicySeverities)
+		}
+		policy.require = iter.Value().LookupPath(cue.ParsePath("require"))
+		if !policy.require.Exists() {
+			return nil, fmt.Errorf("%s: %s: require is missing", settingsFile, policy.Name)
+		}
+		policies = append(policies, policy)
+	}
+	return policies, nil
+}
+
+// AppliesTo reports whether the policy applies to the repository
+func (p Policy) AppliesTo(repoPath string, scanPath string) bool {
+	return p.Path == "" || NewPathResolver(scanPath).Match(p.Path, repoPath)
+}
+
+// ViolatedBy reports whether the facts do not fit the policy's require. An
+// unknown age fits only when any age would, so it violates the policies
+// about age.
+func (p Policy) ViolatedBy(facts PolicyFacts) bool {
+	if facts.DaysSinceCommit == nil {
+		newest, oldest := 0, math.MaxInt32
+		return slices.ContainsFunc([]*int{&newest, &oldest}, func(days *int) bool {
+			facts.DaysSinceCommit = days
+			return p.ViolatedBy(facts)
+		})
+	}
+
+	configMu.Lock()
+	defer configMu.Unlock()
+	return p.require.Unify(sharedContext().Encode(facts)).Validate(cue.Concrete(true)) != nil
+}
@@ -0,0 +1,56 @@
+package gori
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPolicy_ViolatedBy(t *testing.T) {
+	configDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configDir)
+	if err := os.Mkdir(filepath.Join(configDir, "gori"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	settings := `policies: [
+	{name: "recent", severity: "error", require: {upstreamed: true} | {days_since_commit: <=3}},
+	{name: "no stashes", require: stashes: 0},
+]
+`
+	if err := os.WriteFile(filepath.Join(configDir, "gori", "gori.cue"), []byte(settings), 0644); err != nil {
+		t.Fatal(err)
+	}
+	policies, err := LoadPolicies()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(policies) != 2 || policies[1].Severity != "warning" {
+		t.Fatalf("got policies %+v, want two with the second a warning", policies)
+	}
+
+	tests := []struct {
+		name  string
+		facts PolicyFacts
+		want  []bool
+	}{
+		{"upstreamed", PolicyFacts{Upstreamed: true, DaysSinceCommit: days(10)}, []bool{false, false}},
+		{"recent work", PolicyFacts{DaysSinceCommit: days(2)}, []bool{false, false}},
+		{"old work", PolicyFacts{DaysSinceCommit: days(4)}, []bool{true, false}},
+		{"stashed", PolicyFacts{Upstreamed: true, Stashes: 1, DaysSinceCommit: days(0)}, []bool{false, true}},
+		{"unknown age", PolicyFacts{}, []bool{true, false}},
+		{"unknown age upstreamed", PolicyFacts{Upstreamed: true}, []bool{false, false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i, policy := range policies {
+				if got := policy.ViolatedBy(tt.facts); got != tt.want[i] {
+					t.Errorf("%s: got %v, want %v", policy.Name, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func days(n int) *int {
+	return &n
+}
@@ -0,0 +1,78 @@
+package gori
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// RootProfile is the check profile of a scan root, from roots in gori.cue:
+// which checks apply to its repositories and how bad failing them is, e.g.
+// strict for ~/work and lax for ~/playground
+type RootProfile struct {
+	// Path is the scan root, absolute or starting with ~/. Scanning a
+	// directory below it uses its profile too.
+	Path string `json:"path"`
+	// Checks are the checks of its repositories, before their own .gori.cue
+	// and ignore entries
+	Checks Checks `json:"checks,omitempty"`
+	// Severity is how bad failing each check is, one of PolicySeverities;
+	// warning when not given
+	Severity map[string]string `json:"severity,omitempty"`
+}
+
+// ProfileFor returns the profile of the scan root, that of the nearest root
+// containing it, or nil when there is none
+func (s *Settings) ProfileFor(scanPath string) *RootProfile {
+	scanAbs := AbsPath(scanPath)
+	var found *RootProfile
+	nearest := ""
+	for i, profile := range s.Roots {
+		root := AbsPath(NewPathResolver("").Resolve(profile.Path))
+		rel, err := filepath.Rel(root, scanAbs)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if len(root) > len(nearest) {
+			found, nearest = &s.Roots[i], root
+		}
+	}
+	return found
+}
+
+// FailedErrors returns the checks of severity error the project fails, after
+// snoozes
+func (p *RootProfile) FailedErrors(project ProjectStatus) []string {
+	if p == nil {
+		return nil
+	}
+	var failed []string
+	for check, fails := range map[string]bool{"dirty": project.IsDirty, "stash": project.HasStash, "upstream": !project.Upstreamed} {
+		if fails && p.Severity[check] == "error" {
+			failed = append(failed, check)
+		}
+	}
+	slices.Sort(failed)
+	return failed
+}
+
+// validateProfiles returns the mistakes in the profiles of the roots
+func validateProfiles(profiles []RootProfile) []string {
+	var problems []string
+	for _, profile := range profiles {
+		if profile.Path == "" {
+			problems = append(problems, "roots: an entry has no path")
+		}
+		for check, severity := range profile.Severity {
+			if !slices.Contains(ValidChecks, check) || check == "all" {
+				problems = append(problems, fmt.Sprintf("roots: %s: unknown check %q", profile.Path, check))
+			}
+			if !slices.Contains(PolicySeverities, severity) {
+				problems = append(problems, fmt.Sprintf("roots: %s: unknown severity %q, use one of %v", profile.Path, severity, PolicySeverities))
+			}
+		}
+	}
+	slices.Sort(problems)
+	return problems
+}
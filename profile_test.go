@@ -0,0 +1,41 @@
+package gori
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSettings_ProfileFor(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	settings := &Settings{Roots: []RootProfile{
+		{Path: "~/src"},
+		{Path: "~/src/work", Severity: map[string]string{"dirty": "error"}},
+	}}
+
+	tests := []struct {
+		scanPath string
+		want     string
+	}{
+		{filepath.Join(home, "src"), "~/src"},
+		{filepath.Join(home, "src", "work"), "~/src/work"},
+		{filepath.Join(home, "src", "work", "team"), "~/src/work"},
+		{filepath.Join(home, "src", "workshop"), "~/src"},
+		{filepath.Join(home, "playground"), ""},
+	}
+	for _, tt := range tests {
+		got := ""
+		if profile := settings.ProfileFor(tt.scanPath); profile != nil {
+			got = profile.Path
+		}
+		if got != tt.want {
+			t.Errorf("ProfileFor(%s) = %q, want %q", tt.scanPath, got, tt.want)
+		}
+	}
+
+	project := NewProject("api", true, true, false)
+	if got, want := settings.Roots[1].FailedErrors(project), []string{"dirty"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FailedErrors() = %v, want %v", got, want)
+	}
+}
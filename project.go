@@ -1,5 +1,14 @@
 package gori
 
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
 // ProjectStatus tracks the status of a Git repository
 type ProjectStatus struct {
 	Path              string
@@ -9,7 +18,36 @@ type ProjectStatus struct {
 	isDirtySnoozed    bool
 	hasStashSnoozed   bool
 	upstreamedSnoozed bool
-	StatusString      string
+	// StatusString lists the changes of the working directory as found when
+	// checking it, empty when it is clean
+	StatusString string
+	// Stashes is how many stashes there are, set aside work like git's
+	// stashes or Mercurial's shelves
+	Stashes int
+	// RemoteURL is the URL of the origin remote, empty when there is none
+	RemoteURL string
+	// LastCommit is the commit time of HEAD, zero when unknown
+	LastCommit time.Time
+	// Orphaned is why the repository's origin is gone, OrphanDeleted or
+	// OrphanArchived, empty when it is not or it was not looked up
+	Orphaned string
+	// Diverged is how the checked out branch and its upstream went apart, nil
+	// when they did not or it is upstreamed
+	Diverged *Divergence
+	// DivergedTags are the tags pointing at another commit here than on
+	// origin, empty when there are none or they were not compared
+	DivergedTags []DivergedTag
+	// CI is the outcome of the CI of HEAD, one of CIPassed, CIFailed or
+	// CIPending, empty when there is none or it was not looked up
+	CI string
+	// PullRequest is the open pull request of the checked out branch, nil
+	// when there is none or it was not looked up
+	PullRequest *PullRequest
+	// Explanation tells why the checks fired, for --explain
+	Explanation []string
+	// SnoozedUntil is when the first of the project's snoozes expires, zero
+	// when nothing is snoozed
+	SnoozedUntil time.Time
 }
 
 func NewProject(path string, isDirty bool, hasStash bool, upstreamed bool) ProjectStatus {
@@ -21,6 +59,61 @@ func NewProject(path string, isDirty bool, hasStash bool, upstreamed bool) Proje
 	}
 }
 
+// Divergence is a branch and its upstream both having commits the other lacks,
+// which takes a rebase or merge to reconcile
+type Divergence struct {
+	Upstream string `json:"upstream"`
+	// Ahead and Behind count the commits only the branch and only its
+	// upstream have
+	Ahead  int `json:"ahead"`
+	Behind int `json:"behind"`
+}
+
+// DivergedTag is a tag of the same name here and on origin which points at
+// another commit in each
+type DivergedTag struct {
+	Name string `json:"name"`
+	// Local and Remote are the commits it points at here and on origin
+	Local  string `json:"local"`
+	Remote string `json:"remote"`
+}
+
+// reasons a clone is orphaned
+const (
+	// OrphanDeleted is a clone whose origin no longer exists
+	OrphanDeleted = "deleted"
+	// OrphanArchived is a clone whose origin was archived
+	OrphanArchived = "archived"
+)
+
 func (p ProjectStatus) Clean() bool {
-	return !(p.IsDirty || p.HasStash || !p.Upstreamed)
+	return !(p.IsDirty || p.HasStash || !p.Upstreamed || p.Orphaned != "" || len(p.DivergedTags) > 0)
+}
+
+// Fingerprint is a hash of the project's findings: what the checks found,
+// the changes of its working directory and its last commit. It changes when
+// the status of the project actually changes. The changes are sorted, as
+// backends like go-git list them in no particular order.
+func (p ProjectStatus) Fingerprint() string {
+	changes := strings.Split(strings.TrimRight(p.StatusString, "\n"), "\n")
+	slices.Sort(changes)
+	sum := sha256.Sum256(fmt.Appendf(nil, "dirty=%t stash=%t stashes=%d upstreamed=%t last_commit=%s orphaned=%s ci=%s diverged=%v tags=%v\n%s",
+		p.IsDirty, p.HasStash, p.Stashes, p.Upstreamed, p.LastCommit.UTC().Format(time.RFC3339), p.Orphaned, p.CI, p.Diverged, p.DivergedTags, strings.Join(changes, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// Snoozed reports whether any of the project's findings are currently snoozed
+func (p ProjectStatus) Snoozed() bool {
+	return p.isDirtySnoozed || p.hasStashSnoozed || p.upstreamedSnoozed
+}
+
+// snoozedUntil records a snooze's expiry, keeping the earliest one
+func (p *ProjectStatus) snoozedUntil(snoozeTime string) {
+	t, err := time.ParseInLocation(time.DateTime, snoozeTime, time.Local)
+	if err != nil {
+		return
+	}
+	if p.SnoozedUntil.IsZero() || t.Before(p.SnoozedUntil) {
+		p.SnoozedUntil = t
+	}
 }
@@ -0,0 +1,65 @@
+package gori
+
+import (
+	"net/url"
+	"strings"
+
+	git "github.com/go-git/go-git/v5"
+)
+
+// OriginURL returns the URL of the repository's origin remote, or an empty
+// string when it has none
+func OriginURL(repoPath string) string {
+	repo, err := OpenRepo(repoPath)
+	if err != nil {
+		return ""
+	}
+	return RepositoryOriginURL(repo)
+}
+
+// RepositoryOriginURL returns the URL of the origin remote of an opened
+// repository, or an empty string when it has none
+func RepositoryOriginURL(repo *git.Repository) string {
+	remote, err := repo.Remote("origin")
+	if err != nil || len(remote.Config().URLs) == 0 {
+		return ""
+	}
+	return remote.Config().URLs[0]
+}
+
+// NormalizeRemoteURL reduces the different notations of a remote URL to a
+// comparable form, e.g. both git@github.com:foo/bar.git and
+// https://github.com/foo/bar/ become github.com/foo/bar
+func NormalizeRemoteURL(remoteURL string) string {
+	normalized := strings.TrimSpace(remoteURL)
+
+	if isWindowsPath(normalized) {
+		normalized = strings.ReplaceAll(normalized, `\`, "/")
+	} else if u, err := url.Parse(normalized); err == nil && u.Scheme != "" && u.Host != "" {
+		normalized = u.Hostname() + u.Path
+	} else if host, path, ok := strings.Cut(normalized, ":"); ok && !strings.Contains(host, "/") {
+		// scp-like syntax, user@host:path
+		if _, h, ok := strings.Cut(host, "@"); ok {
+			host = h
+		}
+		normalized = host + "/" + strings.TrimPrefix(path, "/")
+	}
+
+	normalized = strings.TrimSuffix(normalized, "/")
+	normalized = strings.TrimSuffix(normalized, ".git")
+	return strings.ToLower(normalized)
+}
+
+// isWindowsPath reports whether the remote is a Windows path, like
+// C:\repos\gori or the UNC path \\server\share\gori, which would otherwise
+// pass for the scp-like syntax or a relative path
+func isWindowsPath(remote string) bool {
+	if strings.HasPrefix(remote, `\\`) {
+		return true
+	}
+	if len(remote) < 3 || remote[1] != ':' || remote[2] != '\\' && remote[2] != '/' {
+		return false
+	}
+	drive := remote[0] | 0x20 // lower case
+	return 'a' <= drive && drive <= 'z'
+}
@@ -0,0 +1,27 @@
+package gori
+
+import "testing"
+
+func TestNormalizeRemoteURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{url: "git@github.com:hansbogert/gori.git", want: "github.com/hansbogert/gori"},
+		{url: "https://github.com/hansbogert/gori", want: "github.com/hansbogert/gori"},
+		{url: "https://user@GitHub.com/hansbogert/gori.git/", want: "github.com/hansbogert/gori"},
+		{url: "ssh://git@github.com:22/hansbogert/gori.git", want: "github.com/hansbogert/gori"},
+		{url: "/srv/git/gori.git", want: "/srv/git/gori"},
+		{url: "../upstream", want: "../upstream"},
+		{url: `C:\Users\me\gori.git`, want: "c:/users/me/gori"},
+		{url: "d:/srv/gori", want: "d:/srv/gori"},
+		{url: `\\server\share\gori.git`, want: "//server/share/gori"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			if got := NormalizeRemoteURL(tt.url); got != tt.want {
+				t.Errorf("NormalizeRemoteURL() = %v, expected = %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package gori
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// RepoConfigFile is the name of the file a repository can carry its own
+// policy in
+const RepoConfigFile = ".gori.cue"
+
+// Checks enables or disables individual checks, nil leaves a check as is
+type Checks struct {
+	Dirty    *bool `json:"dirty,omitempty"`
+	Stash    *bool `json:"stash,omitempty"`
+	Upstream *bool `json:"upstream,omitempty"`
+}
+
+// Merge returns the checks with the ones set in over taking precedence
+func (c Checks) Merge(over Checks) Checks {
+	if over.Dirty != nil {
+		c.Dirty = over.Dirty
+	}
+	if over.Stash != nil {
+		c.Stash = over.Stash
+	}
+	if over.Upstream != nil {
+		c.Upstream = over.Upstream
+	}
+	return c
+}
+
+// RepoConfig represents the structure of a repository's .gori.cue file
+type RepoConfig struct {
+	Checks Checks `json:"checks,omitempty"`
+}
+
+// LoadRepoConfig reads the .gori.cue of a repository. A missing file is not an
+// error, an empty RepoConfig is returned instead.
+func LoadRepoConfig(repoPath string) (*RepoConfig, error) {
+	configFile := filepath.Join(repoPath, RepoConfigFile)
+	content, err := os.ReadFile(configFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return &RepoConfig{}, nil
+	}
+	if err != nil {
+		return &RepoConfig{}, fmt.Errorf("reading %s: %w", configFile, err)
+	}
+
+	configMu.Lock()
+	defer configMu.Unlock()
+	val, err := compileConfig(configFile, content)
+	if err != nil {
+		return &RepoConfig{}, fmt.Errorf("compiling %s: %w", configFile, err)
+	}
+
+	var cfg RepoConfig
+	if err := val.Decode(&cfg); err != nil {
+		return &RepoConfig{}, fmt.Errorf("decoding %s: %w", configFile, err)
+	}
+
+	return &cfg, nil
+}
+
+// EffectiveChecks determines the checks of a repository: its own .gori.cue,
+// overridden by its entry in the scan root's ignore config
+func EffectiveChecks(repoPath string, originURL string, config *IgnoreConfig, scanPath string) (Checks, error) {
+	repoConfig, err := LoadRepoConfig(repoPath)
+	checks := repoConfig.Checks
+
+	if config != nil {
+		for _, repo := range config.Repos {
+			if repo.matches(repoPath, originURL, scanPath) {
+				checks = checks.Merge(repo.Checks)
+			}
+		}
+	}
+	return checks, err
+}
+
+// ApplyChecks clears the findings of the disabled checks
+func (p *ProjectStatus) ApplyChecks(checks Checks) {
+	if checks.Dirty != nil && !*checks.Dirty {
+		p.IsDirty = false
+	}
+	if checks.Stash != nil && !*checks.Stash {
+		p.HasStash = false
+	}
+	if checks.Upstream != nil && !*checks.Upstream {
+		p.Upstreamed = true
+	}
+}
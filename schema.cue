@@ -0,0 +1,28 @@
+// Schema of the .goriignore.cue and global ignore.cue files
+
+#IgnoreConfig: {
+	version?: int & >=1
+	repos?: [...#IgnoreRepo]
+}
+
+#IgnoreRepo: {
+	path: string
+	url?: string
+	snooze?: #Snooze
+	checks?: #Checks
+}
+
+// snoozes are stored as "2006-01-02 15:04:05" in local time
+#Until: =~"^[0-9]{4}-[0-9]{2}-[0-9]{2} [0-9]{2}:[0-9]{2}:[0-9]{2}$"
+
+#Snooze: {
+	dirty_workdir?:  #Until
+	stashes?:        #Until
+	not_upstreamed?: #Until
+}
+
+#Checks: {
+	dirty?:    bool
+	stash?:    bool
+	upstream?: bool
+}
@@ -0,0 +1,197 @@
+package gori
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Settings represents the structure of the user's gori.cue file
+type Settings struct {
+	// Editor is the command used by the (o)pen action, e.g. "code ."
+	Editor string `json:"editor,omitempty"`
+	// GitUI is the command used by the (g)it-ui action, e.g. "lazygit"
+	GitUI string `json:"git_ui,omitempty"`
+	// Pager is the command output longer than the terminal is piped through,
+	// e.g. "less -R"; "cat" turns paging off
+	Pager string `json:"pager,omitempty"`
+	// SnoozeExpiryWarning is how long before expiry a snooze is pointed out,
+	// e.g. "3d", "0" disables the warnings
+	SnoozeExpiryWarning string `json:"snooze_expiry_warning,omitempty"`
+	// ShowSnoozed shows snoozed findings instead of hiding them
+	ShowSnoozed bool `json:"show_snoozed,omitempty"`
+	// HyperlinkTarget is what repository names link to in terminals which
+	// support it, "dir" for their directory or "remote" for the web page of
+	// their origin
+	HyperlinkTarget string `json:"hyperlink_target,omitempty"`
+	// ShowAge shows how long ago the last commit of each repository was made
+	ShowAge bool `json:"show_age,omitempty"`
+	// DirtyGrace is how long a working directory has to be dirty before it
+	// is reported, e.g. "4h", so work in progress is not flagged while it is
+	// being edited
+	DirtyGrace string `json:"dirty_grace,omitempty"`
+	// SnoozePruneAfter is how long after expiry a snooze is pruned, e.g. "30d"
+	SnoozePruneAfter string `json:"snooze_prune_after,omitempty"`
+	// AutoPruneSnoozes prunes stale snoozes on every status run
+	AutoPruneSnoozes bool `json:"auto_prune_snoozes,omitempty"`
+	// SnoozeDuration is how long (i)gnore and gori snooze add snooze when no
+	// duration is given, e.g. "1w"
+	SnoozeDuration string `json:"snooze_duration,omitempty"`
+	// SnoozeCheck is the check they snooze when none is given, defaults to all
+	SnoozeCheck string `json:"snooze_check,omitempty"`
+	// NetworkMounts is what discovery does with directories on a network
+	// mount, like NFS, SMB or FUSE, other than the scan root's: skip (the
+	// default), last to check them after the others, or scan
+	NetworkMounts string `json:"network_mounts,omitempty"`
+	// MaxRepoFiles is the number of files above which a directory is
+	// oversized, 0 disables the check
+	MaxRepoFiles int `json:"max_repo_files,omitempty"`
+	// LargeRepos is what discovery does with oversized directories: skip (the
+	// default) or last
+	LargeRepos string `json:"large_repos,omitempty"`
+	// ExcludeDirs are directories discovery leaves out entirely: a name like
+	// "archive" matches directories of that name, a path like
+	// "thirdparty/**" the directory and everything below it, relative to the
+	// scan root unless absolute or starting with ~/
+	ExcludeDirs []string `json:"exclude_dirs,omitempty"`
+	// DiscoveryCacheTTL is how long the repositories found under a scan root
+	// are remembered, e.g. "1h", "0" disables the cache
+	DiscoveryCacheTTL string `json:"discovery_cache_ttl,omitempty"`
+	// PullRequests looks up the pull request of branches which are not
+	// upstreamed, showing them as in review, or upstreamed once it is merged
+	PullRequests bool `json:"pull_requests,omitempty"`
+	// CIStatus shows the CI outcome of HEAD, listing pushed repositories with
+	// failing CI as well
+	CIStatus bool `json:"ci_status,omitempty"`
+	// DetectOrphans flags clones whose origin was deleted or archived
+	DetectOrphans bool `json:"detect_orphans,omitempty"`
+	// CheckTags flags tags pointing at another commit than the tag of the
+	// same name on origin
+	CheckTags bool `json:"check_tags,omitempty"`
+	// HostingCacheTTL is how long answers of the hosting APIs are reused
+	// without asking again, e.g. "15m"; "0" revalidates them every time
+	HostingCacheTTL string `json:"hosting_cache_ttl,omitempty"`
+	// Hosts are the self-hosted GitHub, GitLab, Gitea and Forgejo instances
+	// pull requests are looked up on, github.com, gitlab.com and codeberg.org
+	// are known already
+	Hosts []HostConfig `json:"hosts,omitempty"`
+	// BackupRemote is the remote gori secure pushes the backups to, origin
+	// when not given
+	BackupRemote string `json:"backup_remote,omitempty"`
+	// BackupNamespace is where gori secure puts the backups on that remote,
+	// refs/gori/backup/ when not given
+	BackupNamespace string `json:"backup_namespace,omitempty"`
+	// RunLog is the file every status run appends a JSON line with its
+	// summary to, e.g. "~/.local/state/gori/runs.jsonl"
+	RunLog string `json:"run_log,omitempty"`
+	// Fleet are the hosts gori fleet reports on
+	Fleet []FleetHost `json:"fleet,omitempty"`
+	// Actions are extra commands offered in the visit menu
+	Actions []Action `json:"actions,omitempty"`
+	// Roots are the check profiles of scan roots
+	Roots []RootProfile `json:"roots,omitempty"`
+}
+
+// FleetHost is a machine of the fleet
+type FleetHost struct {
+	// Name is how the host is shown, its ssh destination by default
+	Name string `json:"name,omitempty"`
+	// Host is the scan root on the host, user@server:/srv/repos
+	Host string `json:"host"`
+}
+
+// SettingsPath returns the location of the user's gori.cue file, or a .json,
+// .yaml or .yml variant of it
+func SettingsPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("locating user config dir: %w", err)
+	}
+	return findConfigFile(filepath.Join(configDir, "gori"), "gori"), nil
+}
+
+// CacheDir returns gori's directory for non-essential state, like the prompt
+// history
+func CacheDir() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("locating user cache dir: %w", err)
+	}
+	return filepath.Join(cacheDir, "gori"), nil
+}
+
+// LoadSettings reads the user's gori.cue file. A missing file is not an
+// error, an empty Settings is returned instead.
+func LoadSettings() (*Settings, error) {
+	settingsFile, err := SettingsPath()
+	if err != nil {
+		return &Settings{}, err
+	}
+
+	content, err := os.ReadFile(settingsFile)
+	if os.IsNotExist(err) {
+		return &Settings{}, nil
+	}
+	if err != nil {
+		return &Settings{}, fmt.Errorf("reading %s: %w", settingsFile, err)
+	}
+
+	configMu.Lock()
+	defer configMu.Unlock()
+	val, err := compileConfig(settingsFile, content)
+	if err != nil {
+		return &Settings{}, fmt.Errorf("compiling %s: %w", settingsFile, err)
+	}
+
+	var settings Settings
+	if err := val.Decode(&settings); err != nil {
+		return &Settings{}, fmt.Errorf("decoding %s: %w", settingsFile, err)
+	}
+
+	return &settings, nil
+}
+
+// ExcludedDir reports whether the directory is one of ExcludeDirs, or below
+// one
+func (s *Settings) ExcludedDir(dir string, scanPath string) bool {
+	return s.DirExcluder(scanPath)(dir)
+}
+
+// DirExcluder returns ExcludedDir for the directories of the scan root, with
+// the patterns resolved once for all of them
+func (s *Settings) DirExcluder(scanPath string) func(dir string) bool {
+	resolver := NewPathResolver(scanPath)
+	var names, paths, subtrees, roots []string
+	for _, pattern := range s.ExcludeDirs {
+		if !strings.ContainsAny(pattern, `/\`) {
+			names = append(names, pattern)
+			continue
+		}
+		if subtree, ok := strings.CutSuffix(pattern, "/**"); ok {
+			subtrees = append(subtrees, subtree)
+			roots = append(roots, AbsPath(resolver.Resolve(subtree))+string(filepath.Separator))
+			continue
+		}
+		paths = append(paths, pattern)
+	}
+
+	return func(dir string) bool {
+		for _, name := range names {
+			if matched, _ := filepath.Match(name, filepath.Base(dir)); matched {
+				return true
+			}
+		}
+		for i, subtree := range subtrees {
+			if resolver.Match(subtree, dir) || strings.HasPrefix(AbsPath(dir), roots[i]) {
+				return true
+			}
+		}
+		for _, path := range paths {
+			if resolver.Match(path, dir) {
+				return true
+			}
+		}
+		return false
+	}
+}
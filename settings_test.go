@@ -0,0 +1,30 @@
+package gori
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestSettings_ExcludedDir(t *testing.T) {
+	scanPath := t.TempDir()
+	settings := &Settings{ExcludeDirs: []string{"archive", "old-*", "thirdparty/**", "vendor/lib"}}
+
+	tests := []struct {
+		dir  string
+		want bool
+	}{
+		{"archive", true},
+		{"old-projects", true},
+		{"thirdparty", true},
+		{"thirdparty/zlib", true},
+		{"vendor/lib", true},
+		{"vendor", false},
+		{"thirdparty-tools", false},
+		{"gori", false},
+	}
+	for _, tt := range tests {
+		if got := settings.ExcludedDir(filepath.Join(scanPath, tt.dir), scanPath); got != tt.want {
+			t.Errorf("ExcludedDir(%s) = %v, want %v", tt.dir, got, tt.want)
+		}
+	}
+}
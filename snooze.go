@@ -1,34 +1,98 @@
 package gori
 
 import (
+	"cmp"
+	_ "embed"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
 	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"cuelang.org/go/cue"
-	"cuelang.org/go/cue/cuecontext"
-	"cuelang.org/go/cue/format"
-	"cuelang.org/go/encoding/gocode/gocodec"
+	cueerrors "cuelang.org/go/cue/errors"
 )
 
 // IgnoreConfig represents the structure of the .goriignore.cue file
 type IgnoreConfig struct {
-	Repos []struct {
-		Path   string `json:"path"`
-		Snooze struct {
-			DirtyWorkdir  string `json:"dirty_workdir,omitempty"`
-			Stashes       string `json:"stashes,omitempty"`
-			NotUpstreamed string `json:"not_upstreamed,omitempty"`
-		} `json:"snooze,omitempty"`
-	} `json:"repos"`
+	// Version is the layout of the file, see IgnoreConfigVersion. Files
+	// without one predate versioning and have version 1.
+	Version int          `json:"version,omitempty"`
+	Repos   []IgnoreRepo `json:"repos"`
 }
 
-func parseSnoozeDuration(durationStr string) (time.Duration, error) {
+// IgnoreRepo holds the snoozes of a single repository
+type IgnoreRepo struct {
+	Path string `json:"path"`
+	// URL identifies the repository by its origin, so the snoozes survive
+	// moving the repository. Path is only used when either has no URL.
+	URL    string `json:"url,omitempty"`
+	Snooze Snooze `json:"snooze,omitempty"`
+	// Checks overrides the checks of the repository's own .gori.cue
+	Checks Checks `json:"checks,omitempty"`
+
+	// base is the directory Path is relative to when the entry comes from the
+	// ignore file of a directory above the scan root
+	base string
+}
+
+// matches reports whether the entry is about the repository at repoPath with
+// the given origin URL. The path decides, so other clones of the same origin
+// are not covered; the URL only finds the repository once nothing is left at
+// the path, as it moved.
+func (r IgnoreRepo) matches(repoPath string, originURL string, scanPath string) bool {
+	resolver := r.resolver(scanPath)
+	if resolver.Match(r.Path, repoPath) {
+		return true
+	}
+	if r.URL == "" || originURL == "" || strings.ContainsAny(r.Path, "*?[") {
+		return false
+	}
+	if _, err := os.Stat(resolver.Resolve(r.Path)); !errors.Is(err, os.ErrNotExist) {
+		return false
+	}
+	return NormalizeRemoteURL(r.URL) == NormalizeRemoteURL(originURL)
+}
+
+// resolver returns the resolver of the entry's path, which is relative to the
+// goriignore file location. Entries of the global ignore file are relative to
+// the scan root.
+func (r IgnoreRepo) resolver(scanPath string) PathResolver {
+	return NewPathResolver(cmp.Or(r.base, scanPath))
+}
+
+// Snooze holds until when each check is snoozed, in time.DateTime format
+type Snooze struct {
+	DirtyWorkdir  string `json:"dirty_workdir,omitempty"`
+	Stashes       string `json:"stashes,omitempty"`
+	NotUpstreamed string `json:"not_upstreamed,omitempty"`
+}
+
+// set snoozes the check, or all checks, until the given time
+func (s *Snooze) set(check string, until string) {
+	if check == "all" || check == "dirty" {
+		s.DirtyWorkdir = until
+	}
+	if check == "all" || check == "stash" {
+		s.Stashes = until
+	}
+	if check == "all" || check == "upstream" {
+		s.NotUpstreamed = until
+	}
+}
+
+// ValidChecks lists the check names accepted when snoozing
+var ValidChecks = []string{"dirty", "stash", "upstream", "all"}
+
+// ParseSnoozeDuration parses durations like 1h, 2d, 3w, 4m (months) and 5y
+func ParseSnoozeDuration(durationStr string) (time.Duration, error) {
 
 	durationStr = strings.TrimSpace(strings.ToLower(durationStr))
 
@@ -70,117 +134,480 @@ func parseSnoozeDuration(durationStr string) (time.Duration, error) {
 	return duration, nil
 }
 
-func SnoozeCheck(project ProjectStatus, durationStr string, check string, scanPath string) {
+// FormatDuration renders a duration in the largest unit accepted by snooze
+// durations that fits, e.g. "3w" or "5h"
+func FormatDuration(d time.Duration) string {
+	day := 24 * time.Hour
+	switch {
+	case d >= 365*day:
+		return fmt.Sprintf("%dy", d/(365*day))
+	case d >= 30*day:
+		return fmt.Sprintf("%dm", d/(30*day))
+	case d >= 7*day:
+		return fmt.Sprintf("%dw", d/(7*day))
+	case d >= day:
+		return fmt.Sprintf("%dd", d/day)
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	default:
+		return fmt.Sprintf("%dmin", d/time.Minute)
+	}
+}
+
+// SnoozeEntry is a single snoozed check of a repository
+type SnoozeEntry struct {
+	Repo  string
+	Check string
+	// Until is when the snooze expires, zero if it cannot be parsed
+	Until time.Time
+}
+
+// Expired reports whether the snooze has run out
+func (e SnoozeEntry) Expired() bool {
+	return !e.Until.After(time.Now())
+}
+
+// ExpiringSnoozes returns the snoozes which have not expired yet but will
+// within the window
+func ExpiringSnoozes(config *IgnoreConfig, window time.Duration) []SnoozeEntry {
+	var expiring []SnoozeEntry
+	for _, entry := range SnoozeEntries(config) {
+		if !entry.Expired() && time.Until(entry.Until) <= window {
+			expiring = append(expiring, entry)
+		}
+	}
+	return expiring
+}
+
+// SnoozeEntries lists every snoozed check of the config, in file order
+func SnoozeEntries(config *IgnoreConfig) []SnoozeEntry {
+	var entries []SnoozeEntry
+	for _, repo := range config.Repos {
+		for _, snooze := range []struct{ check, until string }{
+			{"dirty", repo.Snooze.DirtyWorkdir},
+			{"stash", repo.Snooze.Stashes},
+			{"upstream", repo.Snooze.NotUpstreamed},
+		} {
+			if snooze.until == "" {
+				continue
+			}
+			until, _ := time.ParseInLocation(time.DateTime, snooze.until, time.Local)
+			entries = append(entries, SnoozeEntry{Repo: repo.Path, Check: snooze.check, Until: until})
+		}
+	}
+	return entries
+}
+
+// SnoozeCheck snoozes a check of the project from the visit loop, reporting
+// problems to the user
+func SnoozeCheck(project ProjectStatus, durationStr string, check string, scanPath string, dryRun bool) {
+	if err := AddSnooze(scanPath, project.Path, durationStr, check, "", dryRun); err != nil {
+		fmt.Println("Error:", err)
+	}
+}
+
+// AddSnooze snoozes the check, or all checks, of the project for the given
+// duration in the .goriignore.cue of the scan path. The reason, if any, ends up
+// in the snooze log. With dryRun the change is printed instead.
+func AddSnooze(scanPath string, projectPath string, durationStr string, check string, reason string, dryRun bool) error {
+	return AddSnoozes(scanPath, []string{projectPath}, durationStr, check, reason, dryRun)
+}
+
+// AddSnoozes snoozes the check of several projects at once, as AddSnooze does
+func AddSnoozes(scanPath string, projectPaths []string, durationStr string, check string, reason string, dryRun bool) error {
+	if !slices.Contains(ValidChecks, check) {
+		return fmt.Errorf("unknown check %q, use one of %v", check, ValidChecks)
+	}
+
+	duration, err := ParseSnoozeDuration(durationStr)
+	if err != nil {
+		return err
+	}
+
+	config, err := LoadIgnoreConfig(scanPath)
+	if errors.Is(err, os.ErrNotExist) {
+		config = &IgnoreConfig{Version: IgnoreConfigVersion}
+	} else if err != nil {
+		return err
+	}
+
+	snoozeUntil := time.Now().Add(duration).Format(time.DateTime)
+
+	var changes []SnoozeLogEntry
+	for _, projectPath := range projectPaths {
+		i := findRepo(config, projectPath, scanPath)
+		if i >= 0 {
+			config.Repos[i].Snooze.set(check, snoozeUntil)
+		} else {
+			newRepo := IgnoreRepo{Path: NewPathResolver(scanPath).Stored(projectPath), URL: OriginURL(projectPath)}
+			newRepo.Snooze.set(check, snoozeUntil)
+			config.Repos = append(config.Repos, newRepo)
+			i = len(config.Repos) - 1
+		}
+		changes = append(changes, SnoozeLogEntry{Action: "add", Repo: config.Repos[i].Path, Check: check, Duration: durationStr, Reason: reason})
+	}
+
+	// Now, write the updated config back to the file
+	if err := writeIgnoreConfig(config, scanPath, dryRun); err != nil {
+		return fmt.Errorf("writing ignore file: %w", err)
+	}
+	if dryRun {
+		return nil
+	}
+	for _, change := range changes {
+		logSnoozeChange(scanPath, change)
+	}
+	return nil
+}
+
+// UnsnoozeCheck removes the snooze of the given check for a project. When a
+// duration is given the snooze is instead shortened to expire after that
+// duration, snoozes already expiring earlier are left alone.
+func UnsnoozeCheck(project ProjectStatus, durationStr string, check string, scanPath string, dryRun bool) {
 	config, err := LoadIgnoreConfig(scanPath)
 	if err != nil {
-		config = &IgnoreConfig{}
+		fmt.Println("Nothing to unsnooze:", err)
+		return
 	}
 
-	validChecks := []string{"dirty", "stash", "upstream", "all"}
-	isValcheck := slices.Contains(validChecks, check)
-	if !isValcheck {
+	if !slices.Contains(ValidChecks, check) {
 		fmt.Println("Invalid check specified.")
 		return
 	}
 
-	duration, err := parseSnoozeDuration(durationStr)
-	if err != nil {
-		fmt.Println("Invalid duration format:", err)
+	var snoozeUntil time.Time
+	if durationStr != "" {
+		duration, err := ParseSnoozeDuration(durationStr)
+		if err != nil {
+			fmt.Println("Invalid duration format:", err)
+			return
+		}
+		snoozeUntil = time.Now().Add(duration)
+	}
+
+	i := findRepo(config, project.Path, scanPath)
+	if i < 0 {
+		fmt.Println("Project is not snoozed.")
 		return
 	}
 
-	snoozeUntil := time.Now().Add(duration).Format(time.DateTime)
+	repoPath := config.Repos[i].Path
+	if shortenSnoozes(config, i, check, snoozeUntil) == 0 {
+		return
+	}
 
-	found := false
-	for i, repo := range config.Repos {
-		if repo.Path == project.Path {
-			if check == "all" {
-				config.Repos[i].Snooze.DirtyWorkdir = snoozeUntil
-				config.Repos[i].Snooze.Stashes = snoozeUntil
-				config.Repos[i].Snooze.NotUpstreamed = snoozeUntil
-			} else {
-				switch check {
-				case "dirty":
-					config.Repos[i].Snooze.DirtyWorkdir = snoozeUntil
-				case "stash":
-					config.Repos[i].Snooze.Stashes = snoozeUntil
-				case "upstream":
-					config.Repos[i].Snooze.NotUpstreamed = snoozeUntil
-				}
+	if err := writeIgnoreConfig(config, scanPath, dryRun); err != nil {
+		fmt.Println("Error writing ignore file:", err)
+		return
+	}
+	if dryRun {
+		return
+	}
+	if durationStr == "" {
+		logSnoozeChange(scanPath, SnoozeLogEntry{Action: "remove", Repo: repoPath, Check: check})
+	} else {
+		logSnoozeChange(scanPath, SnoozeLogEntry{Action: "shorten", Repo: repoPath, Check: check, Duration: durationStr})
+	}
+}
+
+// RemoveSnoozes removes the snoozes of the check from every repo whose path
+// matches the glob pattern, and returns how many snoozes were removed. The
+// reason, if any, ends up in the snooze log. With dryRun the changes are
+// printed instead.
+func RemoveSnoozes(scanPath string, pattern string, check string, reason string, dryRun bool) (int, error) {
+	if !slices.Contains(ValidChecks, check) {
+		return 0, fmt.Errorf("unknown check %q, use one of %v", check, ValidChecks)
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return 0, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+
+	config, err := LoadIgnoreConfig(scanPath)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	var changes []SnoozeLogEntry
+	// backwards, as entries without snoozes left are dropped
+	for i := len(config.Repos) - 1; i >= 0; i-- {
+		repoPath := filepath.ToSlash(filepath.Clean(config.Repos[i].Path))
+		if matched, _ := path.Match(pattern, repoPath); matched {
+			if n := shortenSnoozes(config, i, check, time.Time{}); n > 0 {
+				removed += n
+				changes = append(changes, SnoozeLogEntry{Action: "remove", Repo: repoPath, Check: check, Reason: reason})
 			}
-			found = true
-			break
-		}
-	}
-
-	if !found {
-		newRepo := struct {
-			Path   string `json:"path"`
-			Snooze struct {
-				DirtyWorkdir  string `json:"dirty_workdir,omitempty"`
-				Stashes       string `json:"stashes,omitempty"`
-				NotUpstreamed string `json:"not_upstreamed,omitempty"`
-			} `json:"snooze,omitempty"`
-		}{
-			Path: getRelativePath(project.Path, scanPath),
-		}
-		if check == "all" {
-			newRepo.Snooze.DirtyWorkdir = snoozeUntil
-			newRepo.Snooze.Stashes = snoozeUntil
-			newRepo.Snooze.NotUpstreamed = snoozeUntil
-		} else {
-			switch check {
-			case "dirty":
-				newRepo.Snooze.DirtyWorkdir = snoozeUntil
-			case "stash":
-				newRepo.Snooze.Stashes = snoozeUntil
-			case "upstream":
-				newRepo.Snooze.NotUpstreamed = snoozeUntil
+		}
+	}
+
+	if removed == 0 {
+		return 0, nil
+	}
+	if err := writeIgnoreConfig(config, scanPath, dryRun); err != nil || dryRun {
+		return removed, err
+	}
+	for _, change := range slices.Backward(changes) {
+		logSnoozeChange(scanPath, change)
+	}
+	return removed, nil
+}
+
+// PruneSnoozes drops the snoozes which expired longer than expiredFor ago,
+// and the entries of repos which no longer exist. An entry whose path is gone
+// is kept when its URL is the origin of one of the repositories found, by
+// path, as the repository moved there. It returns a description of everything
+// dropped. With dryRun the changes are printed instead.
+func PruneSnoozes(scanPath string, expiredFor time.Duration, remotes map[string]string, dryRun bool) ([]string, error) {
+	config, err := LoadIgnoreConfig(scanPath)
+	if err != nil {
+		return nil, err
+	}
+	byURL := reposByURL(remotes)
+
+	var pruned []string
+	var changes []SnoozeLogEntry
+	// backwards, as entries without snoozes left are dropped
+	for i := len(config.Repos) - 1; i >= 0; i-- {
+		repo := config.Repos[i]
+		// a glob names no repository of its own to check for
+		isGlob := strings.ContainsAny(repo.Path, "*?[")
+		moved := repo.URL != "" && len(byURL[NormalizeRemoteURL(repo.URL)]) > 0
+		if _, err := os.Stat(repo.resolver(scanPath).Resolve(repo.Path)); !isGlob && !moved && errors.Is(err, os.ErrNotExist) {
+			config.Repos = slices.Delete(config.Repos, i, i+1)
+			pruned = append(pruned, fmt.Sprintf("%s: no longer exists", repo.Path))
+			changes = append(changes, SnoozeLogEntry{Action: "prune", Repo: repo.Path, Check: "all", Reason: "no longer exists"})
+			continue
+		}
+
+		for _, entry := range SnoozeEntries(&IgnoreConfig{Repos: []IgnoreRepo{repo}}) {
+			if entry.Until.IsZero() || time.Since(entry.Until) < expiredFor {
+				continue
 			}
+			shortenSnoozes(config, i, entry.Check, time.Time{})
+			pruned = append(pruned, fmt.Sprintf("%s: %s snooze expired %s ago", repo.Path, entry.Check, FormatDuration(time.Since(entry.Until))))
+			changes = append(changes, SnoozeLogEntry{Action: "prune", Repo: repo.Path, Check: entry.Check, Reason: "expired"})
 		}
-		config.Repos = append(config.Repos, newRepo)
 	}
 
-	// Now, write the updated config back to the file
-	ctx := cuecontext.New()
-	codec := gocodec.New(ctx, nil)
-	val, err := codec.Decode(config)
+	if len(pruned) == 0 {
+		return nil, nil
+	}
+	slices.Reverse(pruned)
+	if err := writeIgnoreConfig(config, scanPath, dryRun); err != nil || dryRun {
+		return pruned, err
+	}
+	for _, change := range slices.Backward(changes) {
+		logSnoozeChange(scanPath, change)
+	}
+	return pruned, nil
+}
+
+// shortenSnoozes caps the snoozes of the check for the repo at index i, as
+// shortenSnooze does, and drops the repo once nothing of it is snoozed anymore.
+// It returns the number of changed snoozes.
+func shortenSnoozes(config *IgnoreConfig, i int, check string, until time.Time) int {
+	snooze := &config.Repos[i].Snooze
+	fields := map[string]*string{
+		"dirty":    &snooze.DirtyWorkdir,
+		"stash":    &snooze.Stashes,
+		"upstream": &snooze.NotUpstreamed,
+	}
+
+	changed := 0
+	for name, field := range fields {
+		if check != "all" && check != name {
+			continue
+		}
+		if shortened := shortenSnooze(*field, until); shortened != *field {
+			*field = shortened
+			changed++
+		}
+	}
+
+	if snooze.DirtyWorkdir == "" && snooze.Stashes == "" && snooze.NotUpstreamed == "" {
+		config.Repos = slices.Delete(config.Repos, i, i+1)
+	}
+	return changed
+}
+
+// shortenSnooze returns the snooze time capped at until, a zero until removes
+// the snooze entirely
+func shortenSnooze(snoozeTime string, until time.Time) string {
+	if snoozeTime == "" || until.IsZero() {
+		return ""
+	}
+	t, err := time.ParseInLocation(time.DateTime, snoozeTime, time.Local)
+	if err == nil && t.Before(until) {
+		return snoozeTime
+	}
+	return until.Format(time.DateTime)
+}
+
+// findRepo returns the index of the project's entry in the config, or -1
+func findRepo(config *IgnoreConfig, projectPath string, scanPath string) int {
+	originURL := OriginURL(projectPath)
+	for i, repo := range config.Repos {
+		if repo.matches(projectPath, originURL, scanPath) {
+			return i
+		}
+	}
+	return -1
+}
+
+// writeIgnoreConfig writes the config as the ignore file of the scan path,
+// keeping the format of an existing one. With dryRun it prints a diff
+// instead of writing the file.
+func writeIgnoreConfig(config *IgnoreConfig, scanPath string, dryRun bool) error {
+	ignoreFile := IgnoreFile(scanPath)
+	if err := checkWritable(config, ignoreFile); err != nil {
+		return err
+	}
+	b, err := encodeConfigFile(ignoreFile, config)
 	if err != nil {
-		fmt.Println("Error decoding config:", err)
-		return
+		return fmt.Errorf("encoding %s: %w", ignoreFile, err)
 	}
+	if dryRun {
+		old, _ := os.ReadFile(ignoreFile)
+		fmt.Print(lineDiff(ignoreFile, string(old), string(b)))
+		return nil
+	}
+	return os.WriteFile(ignoreFile, b, 0644)
+}
+
+// checkWritable refuses to rewrite a file from a newer gori, which would drop
+// what this gori does not understand of it
+func checkWritable(config *IgnoreConfig, ignoreFile string) error {
+	if config.Version > IgnoreConfigVersion {
+		return fmt.Errorf("%s has version %d, this gori only understands up to version %d", ignoreFile, config.Version, IgnoreConfigVersion)
+	}
+	return nil
+}
 
-	b, err := format.Node(val.Syntax())
+// IgnoreFile returns the ignore file of the scan path: .goriignore.cue, or a
+// .json, .yaml or .yml variant of it
+func IgnoreFile(scanPath string) string {
+	return findConfigFile(scanPath, ".goriignore")
+}
+
+func LoadIgnoreConfig(scanPath string) (*IgnoreConfig, error) {
+	return loadIgnoreFile(IgnoreFile(scanPath))
+}
+
+// GlobalIgnorePath returns the location of the machine-wide ignore.cue file
+func GlobalIgnorePath() (string, error) {
+	configDir, err := os.UserConfigDir()
 	if err != nil {
-		fmt.Println("Error formatting CUE:", err)
-		return
+		return "", fmt.Errorf("locating user config dir: %w", err)
+	}
+	return findConfigFile(filepath.Join(configDir, "gori"), "ignore"), nil
+}
+
+// LoadMergedIgnoreConfig combines the global ignore.cue, the .goriignore.cue
+// files of the directories above the scan root and the scan root's own. The
+// nearer a file is to the scan root, the later its entries come, so its check
+// overrides take precedence; a snooze from any file applies. Missing files are
+// not an error.
+func LoadMergedIgnoreConfig(scanPath string) (*IgnoreConfig, error) {
+	var merged IgnoreConfig
+	var errs []error
+
+	if globalFile, err := GlobalIgnorePath(); err != nil {
+		errs = append(errs, err)
+	} else if global, err := loadIgnoreFile(globalFile); err == nil {
+		merged.Repos = append(merged.Repos, global.Repos...)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		errs = append(errs, err)
+	}
+
+	for _, dir := range parentDirs(scanPath) {
+		parent, err := LoadIgnoreConfig(dir)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, repo := range parent.Repos {
+			repo.base = dir
+			merged.Repos = append(merged.Repos, repo)
+		}
 	}
 
-	ignoreFile := filepath.Join(scanPath, ".goriignore.cue")
-	err = os.WriteFile(ignoreFile, b, 0644)
+	if local, err := LoadIgnoreConfig(scanPath); err == nil {
+		merged.Repos = append(merged.Repos, local.Repos...)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		errs = append(errs, err)
+	}
+
+	return &merged, errors.Join(errs...)
+}
+
+// parentDirs lists the directories above the scan root, the farthest first
+func parentDirs(scanPath string) []string {
+	dir, err := filepath.Abs(scanPath)
 	if err != nil {
-		fmt.Println("Error writing ignore file:", err)
+		return nil
+	}
+	var dirs []string
+	for parent := filepath.Dir(dir); parent != dir; dir, parent = parent, filepath.Dir(parent) {
+		dirs = append(dirs, parent)
 	}
+	slices.Reverse(dirs)
+	return dirs
 }
 
-func LoadIgnoreConfig(scanPath string) (*IgnoreConfig, error) {
-	ignoreFile := filepath.Join(scanPath, ".goriignore.cue")
+//go:embed schema.cue
+var schemaSource string
+
+// compiledSchema is the #IgnoreConfig definition once compiled
+var compiledSchema cue.Value
+
+// ignoreSchema returns the #IgnoreConfig definition, being closed it rejects
+// unknown fields like a misspelled check. The caller holds configMu.
+func ignoreSchema() cue.Value {
+	if !compiledSchema.Exists() {
+		schema := sharedContext().CompileString(schemaSource, cue.Filename("schema.cue"))
+		compiledSchema = schema.LookupPath(cue.ParsePath("#IgnoreConfig"))
+	}
+	return compiledSchema
+}
+
+// warnedNewer holds the ignore files which were warned about being newer than
+// this gori, they are loaded more than once in a run
+var warnedNewer sync.Map
+
+func loadIgnoreFile(ignoreFile string) (*IgnoreConfig, error) {
 	content, err := os.ReadFile(ignoreFile)
 	if err != nil {
 		return nil, fmt.Errorf("reading %s: %w", ignoreFile, err)
 	}
 
-	ctx := cuecontext.New()
-	val := ctx.CompileBytes(content, cue.Filename(ignoreFile))
-	if val.Err() != nil {
-		return nil, fmt.Errorf("compiling %s: %w", ignoreFile, val.Err())
+	configMu.Lock()
+	defer configMu.Unlock()
+	val, err := compileConfig(ignoreFile, content)
+	if err != nil {
+		return nil, fmt.Errorf("compiling %s: %w", ignoreFile, err)
+	}
+
+	// a file from a newer gori may use fields the schema does not know yet,
+	// what this gori does understand of it is still used
+	version, _ := val.LookupPath(cue.ParsePath("version")).Int64()
+	if version > IgnoreConfigVersion {
+		if _, warned := warnedNewer.LoadOrStore(ignoreFile, true); !warned {
+			slog.Warn("config is newer than this gori, update gori to use all of it", "file", ignoreFile, "version", version, "supported", IgnoreConfigVersion)
+		}
+	} else {
+		val = ignoreSchema().Unify(val)
+		if err := val.Validate(cue.Concrete(true)); err != nil {
+			return nil, fmt.Errorf("validating %s: %s", ignoreFile, cueerrors.Details(err, nil))
+		}
 	}
 
 	var cfg IgnoreConfig
 	if err := val.Decode(&cfg); err != nil {
 		return nil, fmt.Errorf("decoding %s: %w", ignoreFile, err)
 	}
-
 	return &cfg, nil
 }
 
@@ -190,39 +617,26 @@ func ApplySnooze(repoPath string, project *ProjectStatus, config *IgnoreConfig,
 	}
 
 	for _, repo := range config.Repos {
-		// The repo.Path is relative to the goriignore file location
-		// Convert it to an absolute path for comparison
-		ignoreFileDir := scanPath
-		if !filepath.IsAbs(scanPath) {
-			absScanPath, _ := filepath.Abs(scanPath)
-			ignoreFileDir = absScanPath
-		}
-
-		// Resolve the repo path relative to the goriignore file directory
-		resolvedPath := filepath.Join(ignoreFileDir, repo.Path)
-		resolvedPath = filepath.Clean(resolvedPath)
-
-		// Also get absolute path for repoPath for comparison
-		absRepoPath, _ := filepath.Abs(repoPath)
-		absRepoPath = filepath.Clean(absRepoPath)
-
-		if resolvedPath == absRepoPath {
+		if repo.matches(repoPath, project.RemoteURL, scanPath) {
 			if project.IsDirty && repo.Snooze.DirtyWorkdir != "" {
 				if isSnoozed(repo.Snooze.DirtyWorkdir) {
 					project.IsDirty = false
 					project.isDirtySnoozed = true
+					project.snoozedUntil(repo.Snooze.DirtyWorkdir)
 				}
 			}
 			if project.HasStash && repo.Snooze.Stashes != "" {
 				if isSnoozed(repo.Snooze.Stashes) {
 					project.HasStash = false
 					project.hasStashSnoozed = true
+					project.snoozedUntil(repo.Snooze.Stashes)
 				}
 			}
 			if !project.Upstreamed && repo.Snooze.NotUpstreamed != "" {
 				if isSnoozed(repo.Snooze.NotUpstreamed) {
 					project.Upstreamed = true
 					project.upstreamedSnoozed = true
+					project.snoozedUntil(repo.Snooze.NotUpstreamed)
 				}
 			}
 		}
@@ -230,25 +644,11 @@ func ApplySnooze(repoPath string, project *ProjectStatus, config *IgnoreConfig,
 }
 
 func isSnoozed(snoozeTime string) bool {
-	t, err := time.Parse(time.DateTime, snoozeTime)
+	// snoozes are written in local time
+	t, err := time.ParseInLocation(time.DateTime, snoozeTime, time.Local)
 	if err != nil {
 		fmt.Printf("Error parsing snooze time: %s\n", err)
 		return false
 	}
 	return time.Now().Before(t)
 }
-
-func getRelativePath(projectPath, scanPath string) string {
-	// Get absolute paths for both
-	absProjectPath, _ := filepath.Abs(projectPath)
-	absScanPath, _ := filepath.Abs(scanPath)
-
-	// Get relative path from scan directory to project
-	relPath, err := filepath.Rel(absScanPath, absProjectPath)
-	if err != nil {
-		// Fallback to original path if we can't compute relative path
-		return projectPath
-	}
-
-	return relPath
-}
@@ -0,0 +1,99 @@
+package gori
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func Test_shortenSnooze(t *testing.T) {
+	until := time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		snoozeTime string
+		until      time.Time
+		want       string
+	}{
+		{
+			name:       "no snooze stays unsnoozed",
+			snoozeTime: "",
+			until:      until,
+			want:       "",
+		},
+		{
+			name:       "zero until removes the snooze",
+			snoozeTime: "2025-06-01 00:00:00",
+			want:       "",
+		},
+		{
+			name:       "later snooze is shortened",
+			snoozeTime: "2025-06-01 00:00:00",
+			until:      until,
+			want:       "2025-05-10 12:00:00",
+		},
+		{
+			name:       "earlier snooze is kept",
+			snoozeTime: "2025-05-01 00:00:00",
+			until:      until,
+			want:       "2025-05-01 00:00:00",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := shortenSnooze(tt.snoozeTime, tt.until); got != tt.want {
+				t.Errorf("shortenSnooze() = %v, expected = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	day := 24 * time.Hour
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{d: 5 * time.Minute, want: "5min"},
+		{d: 3*time.Hour + 59*time.Minute, want: "3h"},
+		{d: 2 * day, want: "2d"},
+		{d: 15 * day, want: "2w"},
+		{d: 90 * day, want: "3m"},
+		{d: 800 * day, want: "2y"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := FormatDuration(tt.d); got != tt.want {
+				t.Errorf("FormatDuration() = %v, expected = %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadIgnoreConfig_rereadsChangedFile(t *testing.T) {
+	dir := t.TempDir()
+	for _, path := range []string{"foo", "bar"} {
+		if err := os.WriteFile(IgnoreFile(dir), []byte(`repos: [{path: "`+path+`"}]`), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		config, err := LoadIgnoreConfig(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := config.Repos[0].Path; got != path {
+			t.Errorf("Repos[0].Path = %v, expected = %v", got, path)
+		}
+	}
+}
+
+func BenchmarkLoadIgnoreConfig(b *testing.B) {
+	dir := b.TempDir()
+	content := `repos: [{path: "foo", snooze: dirty_workdir: "2099-01-01 00:00:00"}, {path: "bar", checks: upstream: false}]`
+	if err := os.WriteFile(IgnoreFile(dir), []byte(content), 0o644); err != nil {
+		b.Fatal(err)
+	}
+	for b.Loop() {
+		if _, err := LoadIgnoreConfig(dir); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package gori
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/user"
+	"path/filepath"
+	"time"
+)
+
+// SnoozeLogFile is the name of the file in the scan root recording every
+// snooze change
+const SnoozeLogFile = ".gorisnooze.log"
+
+// SnoozeLogEntry is a single change of a snooze
+type SnoozeLogEntry struct {
+	Time time.Time `json:"time"`
+	User string    `json:"user,omitempty"`
+	// Action is one of add, remove, shorten, prune or move
+	Action   string `json:"action"`
+	Repo     string `json:"repo"`
+	Check    string `json:"check"`
+	Duration string `json:"duration,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// logSnoozeChange appends the change to the snooze log of the scan path.
+// Failing to do so only warns, the change itself already happened.
+func logSnoozeChange(scanPath string, entry SnoozeLogEntry) {
+	entry.Time = time.Now()
+	if u, err := user.Current(); err == nil {
+		entry.User = u.Username
+	}
+
+	if err := appendSnoozeLog(scanPath, entry); err != nil {
+		slog.Warn("writing snooze log", "err", err)
+	}
+}
+
+func appendSnoozeLog(scanPath string, entry SnoozeLogEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(scanPath, SnoozeLogFile), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// SnoozeLog reads the snooze log of the scan path, oldest change first. A
+// missing log is not an error.
+func SnoozeLog(scanPath string) ([]SnoozeLogEntry, error) {
+	logFile := filepath.Join(scanPath, SnoozeLogFile)
+	f, err := os.Open(logFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []SnoozeLogEntry
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		var entry SnoozeLogEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return entries, fmt.Errorf("%s:%d: %w", logFile, line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
@@ -0,0 +1,78 @@
+package gori
+
+import (
+	"cmp"
+	"fmt"
+	"path/filepath"
+	"slices"
+)
+
+// SortOrders lists the orders accepted by SortProjects
+var SortOrders = []string{"name", "severity", "age", "checks"}
+
+// SortProjects orders the projects in place:
+//   - name: alphabetically by directory name
+//   - severity: unpushed work first, then dirty work trees, then stashes
+//   - age: least recently committed first, those without commits last
+//   - checks: most failing checks first
+//
+// Ties are broken by name.
+func SortProjects(projects []ProjectStatus, order string) error {
+	var primary func(a, b ProjectStatus) int
+	switch order {
+	case "name":
+		primary = func(a, b ProjectStatus) int { return 0 }
+	case "severity":
+		primary = func(a, b ProjectStatus) int { return cmp.Compare(b.severity(), a.severity()) }
+	case "age":
+		primary = func(a, b ProjectStatus) int {
+			// an unknown commit time is not the oldest one
+			if a.LastCommit.IsZero() != b.LastCommit.IsZero() {
+				if a.LastCommit.IsZero() {
+					return 1
+				}
+				return -1
+			}
+			return a.LastCommit.Compare(b.LastCommit)
+		}
+	case "checks":
+		primary = func(a, b ProjectStatus) int { return cmp.Compare(b.failingChecks(), a.failingChecks()) }
+	default:
+		return fmt.Errorf("unknown sort order %q, use one of %v", order, SortOrders)
+	}
+
+	slices.SortStableFunc(projects, func(a, b ProjectStatus) int {
+		return cmp.Or(
+			primary(a, b),
+			cmp.Compare(filepath.Base(a.Path), filepath.Base(b.Path)),
+		)
+	})
+	return nil
+}
+
+// severity weighs the failing checks, losing unpushed work being worse than
+// losing uncommitted work, which is worse than a forgotten stash
+func (p ProjectStatus) severity() int {
+	severity := 0
+	if !p.Upstreamed {
+		severity += 4
+	}
+	if p.IsDirty {
+		severity += 2
+	}
+	if p.HasStash {
+		severity += 1
+	}
+	return severity
+}
+
+// failingChecks counts the checks the project does not pass
+func (p ProjectStatus) failingChecks() int {
+	failing := 0
+	for _, failed := range []bool{!p.Upstreamed, p.IsDirty, p.HasStash} {
+		if failed {
+			failing++
+		}
+	}
+	return failing
+}
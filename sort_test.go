@@ -0,0 +1,53 @@
+package gori
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSortProjects(t *testing.T) {
+	old := NewProject("old", true, false, true)
+	old.LastCommit = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	unpushed := NewProject("unpushed", false, false, false)
+	unpushed.LastCommit = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	everything := NewProject("everything", true, true, false)
+	everything.LastCommit = time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	stash := NewProject("stash", false, true, true)
+	stash.LastCommit = time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	// no commits yet, or their time could not be read
+	empty := NewProject("empty", false, false, true)
+
+	tests := []struct {
+		order   string
+		want    []string
+		wantErr bool
+	}{
+		{order: "name", want: []string{"empty", "everything", "old", "stash", "unpushed"}},
+		{order: "severity", want: []string{"everything", "unpushed", "old", "stash", "empty"}},
+		{order: "age", want: []string{"old", "everything", "stash", "unpushed", "empty"}},
+		{order: "checks", want: []string{"everything", "old", "stash", "unpushed", "empty"}},
+		{order: "size", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.order, func(t *testing.T) {
+			projects := []ProjectStatus{empty, old, unpushed, everything, stash}
+			err := SortProjects(projects, tt.order)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SortProjects() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			var got []string
+			for _, project := range projects {
+				got = append(got, filepath.Base(project.Path))
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("SortProjects() = %v, expected = %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package gori
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ConfigProblem is an issue found in one of the config files
+type ConfigProblem struct {
+	File    string
+	Problem string
+}
+
+func (p ConfigProblem) String() string {
+	return fmt.Sprintf("%s: %s", p.File, p.Problem)
+}
+
+// nestedExcludeDir reports whether the exclude_dirs pattern is a relative path
+// below a directory of the scan root, like thirdparty/zlib, which discovery
+// never gets to
+func nestedExcludeDir(pattern string) bool {
+	pattern = strings.TrimSuffix(pattern, "/**")
+	if filepath.IsAbs(pattern) || strings.HasPrefix(pattern, "~/") || strings.HasPrefix(pattern, `~\`) {
+		return false
+	}
+	return strings.ContainsAny(filepath.Clean(pattern), `/\`)
+}
+
+// ValidateConfig loads every config file applying to the scan path: the
+// settings with their policies and root profiles, the global and scan root's
+// ignore files and the .gori.cue of the repositories directly under it. Missing files are not a problem.
+func ValidateConfig(scanPath string) []ConfigProblem {
+	var problems []ConfigProblem
+
+	settingsFile, _ := SettingsPath()
+	if settings, err := LoadSettings(); err != nil {
+		problems = append(problems, ConfigProblem{settingsFile, err.Error()})
+	} else {
+		if _, err := LoadPolicies(); err != nil {
+			problems = append(problems, ConfigProblem{settingsFile, err.Error()})
+		}
+		for _, problem := range validateProfiles(settings.Roots) {
+			problems = append(problems, ConfigProblem{settingsFile, problem})
+		}
+		for _, pattern := range settings.ExcludeDirs {
+			if _, err := filepath.Match(pattern, ""); err != nil {
+				problems = append(problems, ConfigProblem{settingsFile, fmt.Sprintf("exclude_dirs: %q: %s", pattern, err)})
+			} else if nestedExcludeDir(pattern) {
+				problems = append(problems, ConfigProblem{settingsFile, fmt.Sprintf("exclude_dirs: %q: never matches, discovery only looks at the directories directly under the scan root", pattern)})
+			}
+		}
+	}
+
+	if globalFile, err := GlobalIgnorePath(); err != nil {
+		problems = append(problems, ConfigProblem{"ignore.cue", err.Error()})
+	} else {
+		problems = append(problems, validateIgnoreFile(globalFile, scanPath)...)
+	}
+	problems = append(problems, validateIgnoreFile(IgnoreFile(scanPath), scanPath)...)
+
+	entries, err := os.ReadDir(scanPath)
+	if err != nil {
+		return append(problems, ConfigProblem{scanPath, err.Error()})
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		repoPath := filepath.Join(scanPath, entry.Name())
+		if _, err := LoadRepoConfig(repoPath); err != nil {
+			problems = append(problems, ConfigProblem{filepath.Join(repoPath, RepoConfigFile), err.Error()})
+		}
+	}
+	return problems
+}
+
+func validateIgnoreFile(ignoreFile string, scanPath string) []ConfigProblem {
+	config, err := loadIgnoreFile(ignoreFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return []ConfigProblem{{ignoreFile, err.Error()}}
+	}
+
+	var problems []ConfigProblem
+	for _, problem := range ValidateIgnoreConfig(config, scanPath) {
+		problems = append(problems, ConfigProblem{ignoreFile, problem})
+	}
+	return problems
+}
+
+// ValidateIgnoreConfig reports entries about the same repository, entries
+// whose path does not exist and snoozes which are expired or unreadable
+func ValidateIgnoreConfig(config *IgnoreConfig, scanPath string) []string {
+	var problems []string
+
+	seen := map[string]string{}
+	for _, repo := range config.Repos {
+		resolved := repo.resolver(scanPath).Resolve(repo.Path)
+
+		duplicate := ""
+		for _, key := range []string{"path:" + resolved, "url:" + NormalizeRemoteURL(repo.URL)} {
+			if key == "url:" {
+				continue
+			}
+			if other, ok := seen[key]; ok && duplicate == "" {
+				duplicate = other
+			}
+			seen[key] = repo.Path
+		}
+		if duplicate == repo.Path {
+			problems = append(problems, fmt.Sprintf("%s: listed more than once", repo.Path))
+		} else if duplicate != "" {
+			problems = append(problems, fmt.Sprintf("%s: conflicts with the entry for %s", repo.Path, duplicate))
+		}
+
+		// entries with a URL follow their repository, the path may be stale
+		if repo.URL == "" && !strings.ContainsAny(repo.Path, "*?[") {
+			if _, err := os.Stat(resolved); errors.Is(err, os.ErrNotExist) {
+				problems = append(problems, fmt.Sprintf("%s: does not exist", repo.Path))
+			}
+		}
+	}
+
+	for _, entry := range SnoozeEntries(config) {
+		if entry.Until.IsZero() {
+			problems = append(problems, fmt.Sprintf("%s: %s snooze has an unreadable expiry", entry.Repo, entry.Check))
+		} else if entry.Expired() {
+			problems = append(problems, fmt.Sprintf("%s: %s snooze expired %s ago", entry.Repo, entry.Check, FormatDuration(time.Since(entry.Until))))
+		}
+	}
+	return problems
+}
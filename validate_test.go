@@ -0,0 +1,71 @@
+package gori
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestValidateIgnoreConfig(t *testing.T) {
+	scanPath := t.TempDir()
+	if err := os.Mkdir(filepath.Join(scanPath, "present"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		repos []IgnoreRepo
+		want  []string
+	}{
+		{
+			name:  "existing repo is fine",
+			repos: []IgnoreRepo{{Path: "present", Snooze: Snooze{Stashes: "2999-01-01 00:00:00"}}},
+		},
+		{
+			name:  "missing repo is reported",
+			repos: []IgnoreRepo{{Path: "gone"}},
+			want:  []string{"gone: does not exist"},
+		},
+		{
+			name:  "missing repo with a URL may have moved",
+			repos: []IgnoreRepo{{Path: "gone", URL: "git@example.com:foo/bar.git"}},
+		},
+		{
+			name:  "globs are not looked up",
+			repos: []IgnoreRepo{{Path: "forks/*"}},
+		},
+		{
+			name:  "same path twice",
+			repos: []IgnoreRepo{{Path: "present"}, {Path: "present/"}},
+			want:  []string{"present/: conflicts with the entry for present"},
+		},
+		{
+			name: "same URL twice",
+			repos: []IgnoreRepo{
+				{Path: "a", URL: "git@example.com:foo/bar.git"},
+				{Path: "b", URL: "https://example.com/foo/bar"},
+			},
+			want: []string{"b: conflicts with the entry for a"},
+		},
+		{
+			name:  "expired and unreadable snoozes",
+			repos: []IgnoreRepo{{Path: "present", Snooze: Snooze{DirtyWorkdir: "soon", NotUpstreamed: "2000-01-01 00:00:00"}}},
+			want:  []string{"present: dirty snooze has an unreadable expiry", "present: upstream snooze expired"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ValidateIgnoreConfig(&IgnoreConfig{Repos: tt.repos}, scanPath)
+			// expiry ages depend on today, only compare their start
+			for i := range got {
+				if i < len(tt.want) && len(got[i]) > len(tt.want[i]) {
+					got[i] = got[i][:len(tt.want[i])]
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ValidateIgnoreConfig() = %q, expected = %q", got, tt.want)
+			}
+		})
+	}
+}